@@ -1,23 +1,50 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
+	"strings"
 
+	"github.com/cristiandonosoc/gochart/pkg/backend"
 	"github.com/cristiandonosoc/gochart/pkg/backend/cpp"
+	"github.com/cristiandonosoc/gochart/pkg/backend/csharp"
+	"github.com/cristiandonosoc/gochart/pkg/backend/dot"
+	"github.com/cristiandonosoc/gochart/pkg/backend/golang"
+	"github.com/cristiandonosoc/gochart/pkg/backend/mermaid"
+	"github.com/cristiandonosoc/gochart/pkg/backend/plantuml"
+	scxml_backend "github.com/cristiandonosoc/gochart/pkg/backend/scxml"
+	xstate_backend "github.com/cristiandonosoc/gochart/pkg/backend/xstate"
 	"github.com/cristiandonosoc/gochart/pkg/frontend"
+	"github.com/cristiandonosoc/gochart/pkg/frontend/gochart_lang"
+	"github.com/cristiandonosoc/gochart/pkg/frontend/json"
+	"github.com/cristiandonosoc/gochart/pkg/frontend/scxml"
+	"github.com/cristiandonosoc/gochart/pkg/frontend/xstate"
 	"github.com/cristiandonosoc/gochart/pkg/frontend/yaml"
 	"github.com/cristiandonosoc/gochart/pkg/ir"
 )
 
 func readFrontend(path string) (*frontend.StatechartData, error) {
-	yf := yaml.NewYamlFrontend()
+	// We select the frontend depending on the extension of the file.
+	var gf frontend.GochartFrontend
+	switch ext := filepath.Ext(path); {
+	case strings.HasSuffix(path, ".xstate.json"):
+		gf = xstate.NewXStateFrontend()
+	case ext == ".gochart":
+		gf = gochart_lang.NewGochartLangFrontend()
+	case ext == ".scxml":
+		gf = scxml.NewScxmlFrontend()
+	case ext == ".json":
+		gf = json.NewJsonFrontend()
+	default:
+		gf = yaml.NewYamlFrontend()
+	}
 
-	scdata, err := yf.ProcessFromFile(path)
+	scdata, err := gf.ProcessFromFile(path)
 	if err != nil {
-		return nil, fmt.Errorf("processign statechart yaml %q: %w", path, err)
+		return nil, fmt.Errorf("processing statechart %q: %w", path, err)
 	}
 
 	return scdata, nil
@@ -72,39 +99,117 @@ func ensureDirExists(path string) (bool, error) {
 }
 
 func internalMain() error {
-	var yamlPath string
-	var headerPath string
-	var bodyPath string
-	onlyPrint := true
+	strict := flag.Bool("strict", false, "treat conflicting transitions as errors")
+	backendName := flag.String("backend", "cpp", "backend to generate with: cpp, unreal, go, csharp, dot, mermaid, plantuml, scxml or xstate")
+	printSchema := flag.Bool("schema", false, "print the JSON Schema of the json statechart definitions and exit")
+	queueCapacity := flag.Int("queue-capacity", cpp.DefaultQueueCapacity, "cpp/unreal: how many triggers can be pending while another one is processed")
+	queueOverflow := flag.String("queue-overflow", cpp.Overflow_Assert.String(), "cpp/unreal: what to do with a trigger when the queue is full: assert, drop_newest or drop_oldest")
+	goPackage := flag.String("package", "", "go: package of the generated code, the statechart name in lower case by default")
+	csharpNamespace := flag.String("namespace", "", "csharp: namespace of the generated code, Gochart by default")
+	flag.Parse()
 
-	if len(os.Args) == 2 {
-		yamlPath = os.Args[1]
-	} else if len(os.Args) == 4 {
-		onlyPrint = false
+	if *printSchema {
+		schema, err := json.Schema()
+		if err != nil {
+			return fmt.Errorf("generating the json schema: %w", err)
+		}
+		fmt.Print(string(schema))
+		return nil
+	}
 
-		yamlPath = os.Args[1]
-		headerPath = os.Args[2]
-		bodyPath = os.Args[3]
-	} else {
-		return fmt.Errorf("Usage: gochart <PATH> [<HEADER_PATH> <BODY_PATH>]")
+	args := flag.Args()
+	if len(args) == 0 {
+		return usageError()
 	}
 
-	scdata, err := readFrontend(yamlPath)
+	scdata, err := readFrontend(args[0])
 	if err != nil {
 		return fmt.Errorf("reading frontend: %w", err)
 	}
 
-	sc, err := ir.ProcessStatechartData(scdata)
+	var opts []ir.Option
+	if *strict {
+		opts = append(opts, ir.WithStrict())
+	}
+
+	sc, err := ir.ProcessStatechartData(scdata, opts...)
 	if err != nil {
 		return fmt.Errorf("processing statechart data: %w", err)
 	}
 
-	backend := cpp.NewCppGochartBackend(func(o *cpp.BackendOptions) {
+	// Warnings do not stop the generation, but the user should still see them.
+	for _, diagnostic := range sc.Diagnostics {
+		fmt.Fprintln(os.Stderr, diagnostic)
+	}
+
+	switch *backendName {
+	case "cpp", "unreal":
+		overflow, err := cpp.ParseOverflowPolicy(*queueOverflow)
+		if err != nil {
+			return fmt.Errorf("parsing -queue-overflow: %w", err)
+		}
+
+		// Unreal is a flavor of the C++ backend.
+		flavor := cpp.Flavor_Standard
+		if *backendName == "unreal" {
+			flavor = cpp.Flavor_Unreal
+		}
+
+		return generateCpp(sc, args[1:], func(o *cpp.BackendOptions) {
+			o.QueueCapacity = *queueCapacity
+			o.QueueOverflow = overflow
+			o.Flavor = flavor
+		})
+	case "go":
+		return generateDocument(golang.NewGoGochartBackend(func(o *golang.BackendOptions) {
+			o.Package = *goPackage
+		}), sc, args[1:])
+	case "csharp":
+		return generateDocument(csharp.NewCSharpGochartBackend(func(o *csharp.BackendOptions) {
+			if *csharpNamespace != "" {
+				o.Namespace = *csharpNamespace
+			}
+		}), sc, args[1:])
+	case "dot":
+		return generateDocument(dot.NewDotGochartBackend(), sc, args[1:])
+	case "mermaid":
+		return generateDocument(mermaid.NewMermaidGochartBackend(), sc, args[1:])
+	case "plantuml":
+		return generateDocument(plantuml.NewPlantUMLGochartBackend(), sc, args[1:])
+	case "scxml":
+		return generateDocument(scxml_backend.NewScxmlGochartBackend(), sc, args[1:])
+	case "xstate":
+		return generateDocument(xstate_backend.NewXStateGochartBackend(), sc, args[1:])
+	default:
+		return fmt.Errorf("unknown backend %q", *backendName)
+	}
+}
+
+func usageError() error {
+	return fmt.Errorf("Usage: gochart -schema | gochart [-strict] [-backend cpp|unreal|go|csharp|dot|mermaid|plantuml|scxml|xstate] <PATH> [<HEADER_PATH> <BODY_PATH> | <OUTPUT_PATH>]")
+}
+
+// generateCpp generates the header and body. Without paths they are printed to stdout.
+func generateCpp(sc *ir.Statechart, paths []string, opts ...cpp.Option) error {
+	var headerPath string
+	var bodyPath string
+	onlyPrint := true
+
+	if len(paths) == 2 {
+		onlyPrint = false
+
+		headerPath = paths[0]
+		bodyPath = paths[1]
+	} else if len(paths) != 0 {
+		return usageError()
+	}
+
+	backend := cpp.NewCppGochartBackend(append(opts, func(o *cpp.BackendOptions) {
 		// For now we just assume the include is in the same directory.
 		if headerPath != "" {
 			o.HeaderInclude = filepath.Base(headerPath)
 		}
-	})
+	})...)
 	headerData, bodyData, err := backend.Generate(sc)
 	if err != nil {
 		return fmt.Errorf("generating backend: %w", err)
@@ -140,6 +245,32 @@ func internalMain() error {
 	return nil
 }
 
+// generateDocument generates the single output of |backend|. Without a path it is printed to stdout.
+func generateDocument(backend backend.GochartDocumentBackend, sc *ir.Statechart, paths []string) error {
+	if len(paths) > 1 {
+		return usageError()
+	}
+
+	data, err := backend.Generate(sc)
+	if err != nil {
+		return fmt.Errorf("generating backend: %w", err)
+	}
+
+	if len(paths) == 0 {
+		if _, err := io.Copy(os.Stdout, data); err != nil {
+			return fmt.Errorf("printing output: %w", err)
+		}
+		return nil
+	}
+
+	if err := writeToFile(paths[0], data); err != nil {
+		return fmt.Errorf("writing output: %w", err)
+	}
+	fmt.Printf("Wrote output to %s\n", paths[0])
+
+	return nil
+}
+
 func main() {
 	if err := internalMain(); err != nil {
 		fmt.Println(err)
@@ -1 +0,0 @@
-:: This script requires bazelisk a
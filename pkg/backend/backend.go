@@ -15,3 +15,9 @@ import (
 type GochartBackend interface {
 	Generate(sc *ir.Statechart) (header, body io.Reader, err error)
 }
+
+// GochartDocumentBackend is the interface for backends that generate a single output instead of a
+// header/body pair, like diagrams or interchange formats.
+type GochartDocumentBackend interface {
+	Generate(sc *ir.Statechart) (io.Reader, error)
+}
@@ -0,0 +1,122 @@
+// Package backendtest has the helpers shared by the tests of the document backends. They generate
+// the statecharts in pkg/ir/testdata and compare the output against the golden files in the testdata
+// directory of the backend, which are rewritten with:
+//
+//	go test ./pkg/backend/<backend> -update
+package backendtest
+
+import (
+	"flag"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cristiandonosoc/gochart/pkg/backend"
+	"github.com/cristiandonosoc/gochart/pkg/frontend"
+	"github.com/cristiandonosoc/gochart/pkg/frontend/gochart_lang"
+	"github.com/cristiandonosoc/gochart/pkg/frontend/yaml"
+	"github.com/cristiandonosoc/gochart/pkg/ir"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var update = flag.Bool("update", false, "update the golden files")
+
+// ChartsDir has the statecharts shared by the tests, relative to the directory of a backend.
+const ChartsDir = "../../ir/testdata"
+
+// Version and Time are the ones the golden files are generated with, so that they do not change
+// between runs.
+var (
+	Version = "TEST"
+	Time    = time.Date(2023, time.July, 4, 0, 0, 0, 0, time.UTC)
+)
+
+// ChartNames returns the names of the statecharts in ChartsDir.
+func ChartNames(t *testing.T) []string {
+	t.Helper()
+
+	paths, err := filepath.Glob(filepath.Join(ChartsDir, "*.yaml"))
+	require.NoError(t, err)
+	require.NotEmpty(t, paths)
+
+	var names []string
+	for _, path := range paths {
+		names = append(names, strings.TrimSuffix(filepath.Base(path), ".yaml"))
+	}
+	return names
+}
+
+// ReadStatechart reads the statechart |name| from ChartsDir.
+func ReadStatechart(t *testing.T, name string) *ir.Statechart {
+	t.Helper()
+
+	scdata, err := yaml.NewYamlFrontend().ProcessFromFile(filepath.Join(ChartsDir, name+".yaml"))
+	require.NoError(t, err)
+
+	sc, err := ir.ProcessStatechartData(scdata)
+	require.NoError(t, err)
+
+	return sc
+}
+
+// ParseStatechart reads a statechart written in gochart_lang, for the tests of cases that the shared
+// statecharts do not cover.
+func ParseStatechart(t *testing.T, input string) *ir.Statechart {
+	t.Helper()
+
+	scdata, err := gochart_lang.NewGochartLangFrontend().Process(strings.NewReader(input))
+	require.NoError(t, err)
+
+	sc, err := ir.ProcessStatechartData(scdata)
+	require.NoError(t, err)
+
+	return sc
+}
+
+// Generate returns the output of |b| for |sc|.
+func Generate(t *testing.T, b backend.GochartDocumentBackend, sc *ir.Statechart) string {
+	t.Helper()
+
+	r, err := b.Generate(sc)
+	require.NoError(t, err)
+
+	data, err := io.ReadAll(r)
+	require.NoError(t, err)
+
+	return string(data)
+}
+
+// CheckRoundTrip reads the output of |b| for |sc| with |f|, which reads the same format, and checks
+// that generating the statechart read back gives the same output.
+func CheckRoundTrip(t *testing.T, b backend.GochartDocumentBackend, f frontend.GochartFrontend, sc *ir.Statechart) {
+	t.Helper()
+
+	want := Generate(t, b, sc)
+
+	scdata, err := f.Process(strings.NewReader(want))
+	require.NoError(t, err)
+
+	roundTrip, err := ir.ProcessStatechartData(scdata)
+	require.NoError(t, err)
+
+	assert.Equal(t, want, Generate(t, b, roundTrip))
+}
+
+// CheckGolden compares |got| against the golden file at |path|, or rewrites it with -update.
+func CheckGolden(t *testing.T, path string, got string) {
+	t.Helper()
+
+	if *update {
+		require.NoError(t, os.WriteFile(path, []byte(got), 0644))
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	require.NoError(t, err, "run with -update to create the golden files")
+	assert.Equal(t, string(want), got, "run with -update if the change is intended")
+}
@@ -0,0 +1,109 @@
+package backend
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/cristiandonosoc/gochart/pkg/ir"
+)
+
+// The generated code of every backend calls the same methods over the owner of the statechart:
+//
+//   - State<State>_OnEnter() and State<State>_OnExit() for the default reactions of a state.
+//   - State<State>_OnEnter_<Trigger>(args) and State<State>_OnExit_<Trigger>(args) for the
+//     reactions specific to a trigger.
+//   - Guard<Guard>(args) and Action<Action>(args) for the guards and actions of the transitions.
+//
+// The arguments are the ones of the trigger being processed, so a guard or action shared by
+// transitions of triggers with different arguments is an overloaded method. Languages without
+// overloading name each of them after its trigger instead (see CallbackNaming).
+
+// CallbackNaming is how the guards and actions that are called with different arguments get named.
+type CallbackNaming int
+
+const (
+	// CallbackNaming_Overloads keeps a single name, for languages that support overloading.
+	CallbackNaming_Overloads CallbackNaming = iota
+
+	// CallbackNaming_PerTrigger appends the name of the trigger to them (eg. ActionSpawnDust_Land).
+	// The ones called from null transitions keep the plain name. Guards and actions always called
+	// with the same argument types are not affected.
+	CallbackNaming_PerTrigger
+)
+
+func (cn CallbackNaming) String() string {
+	switch cn {
+	case CallbackNaming_Overloads:
+		return "overloads"
+	case CallbackNaming_PerTrigger:
+		return "per_trigger"
+	}
+
+	return fmt.Sprintf("<invalid callback naming %d>", int(cn))
+}
+
+// CallbackNamer names the guards and actions of the transitions of a statechart.
+type CallbackNamer struct {
+	naming CallbackNaming
+
+	// overloaded holds the plain names that are called with different argument types.
+	overloaded map[string]bool
+}
+
+func NewCallbackNamer(sc *ir.Statechart, naming CallbackNaming) *CallbackNamer {
+	cn := &CallbackNamer{
+		naming:     naming,
+		overloaded: make(map[string]bool),
+	}
+
+	signatures := make(map[string]string)
+	add := func(name string, trigger *ir.Trigger) {
+		signature := Signature(trigger)
+		if previous, ok := signatures[name]; ok && previous != signature {
+			cn.overloaded[name] = true
+		}
+		signatures[name] = signature
+	}
+
+	for _, transition := range sc.Transitions {
+		if transition.HasGuard() {
+			add("Guard"+transition.Guard, transition.Trigger)
+		}
+		for _, action := range transition.Actions {
+			add("Action"+action, transition.Trigger)
+		}
+	}
+
+	return cn
+}
+
+// Guard returns the name of the method that evaluates the guard of |transition|.
+func (cn *CallbackNamer) Guard(transition *ir.Transition) string {
+	return cn.name("Guard"+transition.Guard, transition.Trigger)
+}
+
+// Action returns the name of the method that runs |action| when taking |transition|.
+func (cn *CallbackNamer) Action(transition *ir.Transition, action string) string {
+	return cn.name("Action"+action, transition.Trigger)
+}
+
+func (cn *CallbackNamer) name(name string, trigger *ir.Trigger) string {
+	if cn.naming == CallbackNaming_PerTrigger && cn.overloaded[name] && trigger != nil {
+		return fmt.Sprintf("%s_%s", name, trigger.Name)
+	}
+	return name
+}
+
+// Signature identifies the argument types of the callbacks for |trigger|, which is what tells
+// overloads apart. A nil trigger takes no arguments.
+func Signature(trigger *ir.Trigger) string {
+	if trigger == nil {
+		return ""
+	}
+
+	types := make([]string, 0, len(trigger.Args))
+	for _, arg := range trigger.Args {
+		types = append(types, arg.Type)
+	}
+	return strings.Join(types, ", ")
+}
@@ -0,0 +1,58 @@
+package cpp
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/cristiandonosoc/gochart/pkg/backend"
+	"github.com/cristiandonosoc/gochart/pkg/ir"
+)
+
+// typeMapper translates the C++ type of a trigger argument, as written in the statechart, into the
+// one the generated code uses.
+type typeMapper func(argType string) string
+
+// sameType is the typeMapper that keeps the types as written.
+func sameType(argType string) string {
+	return argType
+}
+
+// argsDeclaration returns the parameters of a function that takes the arguments of |trigger|. A nil
+// trigger takes none.
+func argsDeclaration(trigger *ir.Trigger, mapType typeMapper) string {
+	if trigger == nil {
+		return ""
+	}
+
+	params := make([]string, 0, len(trigger.Args))
+	for _, arg := range trigger.Args {
+		params = append(params, fmt.Sprintf("%s %s", mapType(arg.Type), arg.Name))
+	}
+	return strings.Join(params, ", ")
+}
+
+// callbackCall returns the C++ call to |callback|, forwarding the arguments from a trigger struct
+// named "trigger".
+func callbackCall(callback *backend.Callback) string {
+	var args []string
+	if callback.Trigger != nil {
+		for _, arg := range callback.Trigger.ArgsNameList() {
+			args = append(args, "trigger."+arg)
+		}
+	}
+	return fmt.Sprintf("%s(%s)", callback.Name, strings.Join(args, ", "))
+}
+
+// callbackReturn returns the C++ return type of |callback|.
+func callbackReturn(callback *backend.Callback) string {
+	if callback.IsGuard() {
+		return "bool"
+	}
+	return "void"
+}
+
+// callbackDeclaration returns the declaration of the owner method for |callback|.
+func callbackDeclaration(callback *backend.Callback, mapType typeMapper) string {
+	return fmt.Sprintf("%s %s(%s);",
+		callbackReturn(callback), callback.Name, argsDeclaration(callback.Trigger, mapType))
+}
@@ -16,18 +16,41 @@ type cppGochartBackend struct {
 	options *BackendOptions
 }
 
+// Flavor is the kind of C++ the backend generates.
+type Flavor int
+
+const (
+	// Flavor_Standard generates plain C++17 within the gochart namespace.
+	Flavor_Standard Flavor = iota
+
+	// Flavor_Unreal generates the same runtime, but wrapped for Unreal Engine: the states and
+	// triggers are reflected types and an actor component exposes the statechart to Blueprints. See
+	// unreal.go.
+	Flavor_Unreal
+)
+
 type BackendOptions struct {
 	HeaderInclude string
 	Time          time.Time
 	Version       string
+
+	// QueueCapacity is how many triggers can be pending while another one is processed.
+	QueueCapacity int
+
+	// QueueOverflow is what happens when a trigger arrives and the queue is full.
+	QueueOverflow OverflowPolicy
+
+	Flavor Flavor
 }
 
 type Option func(*BackendOptions)
 
 func NewCppGochartBackend(opts ...Option) *cppGochartBackend {
 	options := &BackendOptions{
-		Version: "DEVELOPMENT",
-		Time:    time.Now(),
+		Version:       "DEVELOPMENT",
+		Time:          time.Now(),
+		QueueCapacity: DefaultQueueCapacity,
+		QueueOverflow: Overflow_Assert,
 	}
 	for _, opt := range opts {
 		opt(options)
@@ -39,17 +62,21 @@ func NewCppGochartBackend(opts ...Option) *cppGochartBackend {
 }
 
 func (cpp *cppGochartBackend) Generate(sc *ir.Statechart) (_header, _body io.Reader, _err error) {
-	tm, err := newTemplateManager(sc)
+	if err := cpp.options.validate(); err != nil {
+		return nil, nil, fmt.Errorf("invalid options: %w", err)
+	}
+
+	tm, err := newTemplateManager(sc, cpp.options)
 	if err != nil {
 		return nil, nil, fmt.Errorf("building new template manager: %w", err)
 	}
 
-	header, err := tm.generateHeader(cpp.options)
+	header, err := tm.generateHeader()
 	if err != nil {
 		return nil, nil, fmt.Errorf("generating header: %w", err)
 	}
 
-	body, err := tm.generateBody(cpp.options)
+	body, err := tm.generateBody()
 	if err != nil {
 		return nil, nil, fmt.Errorf("generating body: %w", err)
 	}
@@ -0,0 +1,351 @@
+package cpp
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cristiandonosoc/gochart/pkg/backend"
+	"github.com/cristiandonosoc/gochart/pkg/frontend/gochart_lang"
+	"github.com/cristiandonosoc/gochart/pkg/frontend/yaml"
+	"github.com/cristiandonosoc/gochart/pkg/ir"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// The tests run the backend over the statecharts in pkg/ir/testdata. The generated code and the
+// output of running it are compared against the golden files in testdata, which are rewritten with:
+//
+//	go test ./pkg/backend/cpp -update
+var update = flag.Bool("update", false, "update the golden files")
+
+const chartsDir = "../../ir/testdata"
+
+func chartNames(t *testing.T) []string {
+	t.Helper()
+
+	paths, err := filepath.Glob(filepath.Join(chartsDir, "*.yaml"))
+	require.NoError(t, err)
+	require.NotEmpty(t, paths)
+
+	var names []string
+	for _, path := range paths {
+		names = append(names, strings.TrimSuffix(filepath.Base(path), ".yaml"))
+	}
+	return names
+}
+
+func readStatechart(t *testing.T, name string) *ir.Statechart {
+	t.Helper()
+
+	scdata, err := yaml.NewYamlFrontend().ProcessFromFile(filepath.Join(chartsDir, name+".yaml"))
+	require.NoError(t, err)
+
+	sc, err := ir.ProcessStatechartData(scdata)
+	require.NoError(t, err)
+
+	return sc
+}
+
+func generate(t *testing.T, sc *ir.Statechart, name string, opts ...Option) (header, body string) {
+	t.Helper()
+
+	opts = append([]Option{func(o *BackendOptions) {
+		o.HeaderInclude = name + ".h"
+		o.Version = "TEST"
+		o.Time = time.Date(2023, time.July, 4, 0, 0, 0, 0, time.UTC)
+	}}, opts...)
+	backend := NewCppGochartBackend(opts...)
+	hr, br, err := backend.Generate(sc)
+	require.NoError(t, err)
+
+	h, err := io.ReadAll(hr)
+	require.NoError(t, err)
+	b, err := io.ReadAll(br)
+	require.NoError(t, err)
+
+	return string(h), string(b)
+}
+
+func checkGolden(t *testing.T, path string, got string) {
+	t.Helper()
+
+	if *update {
+		require.NoError(t, os.WriteFile(path, []byte(got), 0644))
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	require.NoError(t, err, "run with -update to create the golden files")
+	assert.Equal(t, string(want), got, "run with -update if the change is intended")
+}
+
+func TestGenerateGolden(t *testing.T) {
+	for _, name := range chartNames(t) {
+		t.Run(name, func(t *testing.T) {
+			header, body := generate(t, readStatechart(t, name), name)
+			checkGolden(t, filepath.Join("testdata", name+".h.golden"), header)
+			checkGolden(t, filepath.Join("testdata", name+".cpp.golden"), body)
+		})
+	}
+}
+
+// TestCompileAndRun builds the generated code along with an owner that logs every callback, and
+// runs it through activation, every trigger and deactivation. The triggers are fired in two rounds,
+// so that the second one starts from where the first one left (eg. to restore history). The run
+// golden files are also checked against the simulator (see pkg/sim), and the other backends compare
+// their own runs against them.
+func TestCompileAndRun(t *testing.T) {
+	compiler := findCompiler(t)
+
+	for _, name := range chartNames(t) {
+		t.Run(name, func(t *testing.T) {
+			sc := readStatechart(t, name)
+			header, body := generate(t, sc, name)
+
+			out := compileAndRun(t, compiler, map[string]string{
+				name + ".h":   header,
+				name + ".cpp": body,
+				"main.cpp":    driver(sc, name),
+			})
+
+			checkGolden(t, filepath.Join("testdata", name+".run.golden"), out)
+		})
+	}
+}
+
+// compileAndRun writes |files| into a temporary directory, compiles all the .cpp ones into a binary
+// and returns the output of running it.
+func compileAndRun(t *testing.T, compiler string, files map[string]string) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	args := []string{"-std=c++17", "-Wall", "-Wextra", "-Werror", "-Wno-unused-parameter"}
+	for filename, content := range files {
+		path := filepath.Join(dir, filename)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+		if filepath.Ext(filename) == ".cpp" {
+			args = append(args, path)
+		}
+	}
+
+	binary := filepath.Join(dir, "statechart")
+	out, err := exec.Command(compiler, append(args, "-o", binary)...).CombinedOutput()
+	require.NoError(t, err, "compiling:\n%s", out)
+
+	out, err = exec.Command(binary).CombinedOutput()
+	require.NoError(t, err, "running:\n%s", out)
+
+	return string(out)
+}
+
+// findCompiler returns the C++ compiler to use: $CXX or the first one found in the PATH.
+func findCompiler(t *testing.T) string {
+	t.Helper()
+
+	if cxx := os.Getenv("CXX"); cxx != "" {
+		return cxx
+	}
+
+	for _, candidate := range []string{"g++", "clang++", "c++"} {
+		if path, err := exec.LookPath(candidate); err == nil {
+			return path
+		}
+	}
+
+	t.Skip("no C++ compiler found")
+	return ""
+}
+
+// TestRunToCompletion checks that the triggers raised from a reaction are processed once the
+// current step completes, and what happens to them when they do not fit in the queue. The triggers
+// are raised with temporaries, which the queue has to copy.
+func TestRunToCompletion(t *testing.T) {
+	compiler := findCompiler(t)
+
+	input := `
+statechart Relay {
+	trigger Start
+	trigger Step("const std::string& label")
+
+	state Idle {
+		initial
+		transition Running { trigger Start }
+	}
+
+	state Running {
+		default_enter
+		transition Running { trigger Step action Log internal }
+	}
+}`
+
+	scdata, err := gochart_lang.NewGochartLangFrontend().Process(strings.NewReader(input))
+	require.NoError(t, err)
+	sc, err := ir.ProcessStatechartData(scdata)
+	require.NoError(t, err)
+
+	main := `#include "relay.h"
+
+#include <cstdio>
+#include <string>
+
+struct Owner
+{
+	gochart::StatechartRelay<Owner>* Relay = nullptr;
+
+	void StateRunning_OnEnter()
+	{
+		std::printf("enter Running\n");
+		for (int i = 1; i <= 3; i++) {
+			Relay->TriggerStep(std::string("step ") + std::to_string(i));
+		}
+		std::printf("enter Running done\n");
+	}
+
+	void ActionLog(const std::string& label) { std::printf("%s\n", label.c_str()); }
+};
+
+int main()
+{
+	Owner owner;
+	auto relay = gochart::StatechartRelay<Owner>::Create(&owner);
+	owner.Relay = relay.get();
+
+	relay->Activate();
+	relay->TriggerStart();
+	relay->Deactivate();
+	return 0;
+}
+`
+
+	testcases := []struct {
+		capacity int
+		overflow OverflowPolicy
+		want     string
+	}{
+		{
+			capacity: DefaultQueueCapacity,
+			overflow: Overflow_Assert,
+			want:     "enter Running\nenter Running done\nstep 1\nstep 2\nstep 3\n",
+		},
+		{
+			capacity: 2,
+			overflow: Overflow_DropNewest,
+			want:     "enter Running\nenter Running done\nstep 1\nstep 2\n",
+		},
+		{
+			capacity: 2,
+			overflow: Overflow_DropOldest,
+			want:     "enter Running\nenter Running done\nstep 2\nstep 3\n",
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.overflow.String(), func(t *testing.T) {
+			backend := NewCppGochartBackend(func(o *BackendOptions) {
+				o.HeaderInclude = "relay.h"
+				o.QueueCapacity = tc.capacity
+				o.QueueOverflow = tc.overflow
+			})
+			hr, br, err := backend.Generate(sc)
+			require.NoError(t, err)
+			header, err := io.ReadAll(hr)
+			require.NoError(t, err)
+			body, err := io.ReadAll(br)
+			require.NoError(t, err)
+
+			got := compileAndRun(t, compiler, map[string]string{
+				"relay.h":   string(header),
+				"relay.cpp": string(body),
+				"main.cpp":  main,
+			})
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestInvalidQueueOptions(t *testing.T) {
+	sc := readStatechart(t, "simple")
+
+	_, _, err := NewCppGochartBackend(func(o *BackendOptions) { o.QueueCapacity = 0 }).Generate(sc)
+	assert.ErrorContains(t, err, "queue capacity must be positive, got 0")
+
+	_, _, err = NewCppGochartBackend(func(o *BackendOptions) { o.QueueOverflow = 42 }).Generate(sc)
+	assert.ErrorContains(t, err, "invalid queue overflow policy 42")
+}
+
+func TestValueType(t *testing.T) {
+	testcases := []struct {
+		argType string
+		want    string
+	}{
+		{"int", "int"},
+		{"const std::string&", "std::string"},
+		{"const FVector &", "FVector"},
+		{"std::vector<int>&&", "std::vector<int>"},
+		{"const char*", "const char*"},
+		{"Foo*", "Foo*"},
+	}
+
+	tc := &templateContext{mapType: sameType}
+	for _, testcase := range testcases {
+		assert.Equal(t, testcase.want, tc.ValueType(testcase.argType), testcase.argType)
+	}
+}
+
+// driver returns a main.cpp that drives the generated statechart of |sc|. The owner logs every
+// callback and lets every guard pass.
+func driver(sc *ir.Statechart, name string) string {
+	var sb strings.Builder
+
+	implName := fmt.Sprintf("gochart::Statechart%sImpl", sc.Name)
+
+	fmt.Fprintf(&sb, "#include \"%s.h\"\n\n#include <cstdio>\n\n", name)
+
+	sb.WriteString("struct Owner\n{\n")
+	for _, method := range backend.NewTransitionModel(sc, backend.CallbackNaming_Overloads).OwnerMethods {
+		ret, methodName, args := callbackReturn(method), method.Name, argsDeclaration(method.Trigger, sameType)
+		fmt.Fprintf(&sb, "\t%s %s(%s)\n\t{\n", ret, methodName, args)
+		fmt.Fprintf(&sb, "\t\tstd::printf(\"  %s\\n\");\n", methodName)
+		if ret == "bool" {
+			sb.WriteString("\t\treturn true;\n")
+		}
+		sb.WriteString("\t}\n")
+	}
+	sb.WriteString("};\n\n")
+
+	fmt.Fprintf(&sb, "using Statechart = gochart::Statechart%s<Owner>;\n\n", sc.Name)
+	sb.WriteString("static void PrintActive(const Statechart& sc)\n{\n")
+	sb.WriteString("\tstd::printf(\"active:\");\n")
+	fmt.Fprintf(&sb, "\tfor (std::size_t i = 0; i < %s::kStateCount; i++) {\n", implName)
+	fmt.Fprintf(&sb, "\t\tauto state = static_cast<%s::StateKind>(i);\n", implName)
+	sb.WriteString("\t\tif (sc.IsActive(state)) {\n")
+	fmt.Fprintf(&sb, "\t\t\tstd::printf(\" %%s\", %s::ToString(state));\n", implName)
+	sb.WriteString("\t\t}\n\t}\n\tstd::printf(\"\\n\");\n}\n\n")
+
+	sb.WriteString("int main()\n{\n")
+	sb.WriteString("\tOwner owner;\n\tauto sc = Statechart::Create(&owner);\n\n")
+	sb.WriteString("\tstd::printf(\"Activate\\n\");\n\tsc->Activate();\n\tPrintActive(*sc);\n")
+	sb.WriteString("\n\tfor (int round = 0; round < 2; round++) {")
+	for _, trigger := range sc.Triggers {
+		args := make([]string, len(trigger.Args))
+		for i := range args {
+			args[i] = "{}"
+		}
+		fmt.Fprintf(&sb, "\n\t\tstd::printf(\"Trigger%s\\n\");\n", trigger.Name)
+		fmt.Fprintf(&sb, "\t\tsc->Trigger%s(%s);\n\t\tPrintActive(*sc);\n", trigger.Name, strings.Join(args, ", "))
+	}
+	sb.WriteString("\t}\n")
+	sb.WriteString("\n\tstd::printf(\"Deactivate\\n\");\n\tsc->Deactivate();\n\tPrintActive(*sc);\n")
+	sb.WriteString("\treturn 0;\n}\n")
+
+	return sb.String()
+}
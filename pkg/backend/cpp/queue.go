@@ -0,0 +1,66 @@
+package cpp
+
+import (
+	"fmt"
+)
+
+// The generated statechart processes triggers run-to-completion: a trigger is fully handled,
+// including the null transitions it enables, before the next one starts. Triggers raised while
+// another one is being handled (eg. from within a reaction or an action) are stored in a fixed
+// capacity queue and processed in order once the current step completes.
+
+// DefaultQueueCapacity is how many pending triggers the generated queue holds, unless configured.
+const DefaultQueueCapacity = 32
+
+// OverflowPolicy is what the generated code does when a trigger arrives and the queue is full.
+type OverflowPolicy int
+
+const (
+	// Overflow_Assert asserts, as a full queue most likely means that reactions keep raising
+	// triggers. With asserts disabled, the new trigger is dropped.
+	Overflow_Assert OverflowPolicy = iota
+
+	// Overflow_DropNewest drops the trigger that did not fit.
+	Overflow_DropNewest
+
+	// Overflow_DropOldest drops the oldest pending trigger to make room for the new one.
+	Overflow_DropOldest
+)
+
+func (op OverflowPolicy) String() string {
+	switch op {
+	case Overflow_Assert:
+		return "assert"
+	case Overflow_DropNewest:
+		return "drop_newest"
+	case Overflow_DropOldest:
+		return "drop_oldest"
+	}
+
+	return fmt.Sprintf("<invalid overflow policy %d>", int(op))
+}
+
+// ParseOverflowPolicy returns the policy named as by |OverflowPolicy.String|.
+func ParseOverflowPolicy(policy string) (OverflowPolicy, error) {
+	for _, op := range []OverflowPolicy{Overflow_Assert, Overflow_DropNewest, Overflow_DropOldest} {
+		if op.String() == policy {
+			return op, nil
+		}
+	}
+
+	return Overflow_Assert, fmt.Errorf("unknown overflow policy %q, expected \"assert\", \"drop_newest\" or \"drop_oldest\"", policy)
+}
+
+func (options *BackendOptions) validate() error {
+	if options.QueueCapacity <= 0 {
+		return fmt.Errorf("queue capacity must be positive, got %d", options.QueueCapacity)
+	}
+
+	switch options.QueueOverflow {
+	case Overflow_Assert, Overflow_DropNewest, Overflow_DropOldest:
+	default:
+		return fmt.Errorf("invalid queue overflow policy %d", int(options.QueueOverflow))
+	}
+
+	return nil
+}
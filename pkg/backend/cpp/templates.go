@@ -5,21 +5,30 @@ import (
 	"embed"
 	"fmt"
 	"io"
+	"strings"
 	"text/template"
 
 	"github.com/Masterminds/sprig/v3"
 
+	"github.com/cristiandonosoc/gochart/pkg/backend"
 	"github.com/cristiandonosoc/gochart/pkg/ir"
 )
 
-//go:embed header.template.h body.template.cpp
+//go:embed *.tmpl
 var embeddedFS embed.FS
 
 type embedPath string
 
 const (
-	headerFilename embedPath = "header.template.h"
-	bodyFilename   embedPath = "body.template.cpp"
+	headerFilename embedPath = "header.h.tmpl"
+	bodyFilename   embedPath = "body.cpp.tmpl"
+
+	unrealHeaderFilename embedPath = "unreal.h.tmpl"
+	unrealBodyFilename   embedPath = "unreal.cpp.tmpl"
+
+	// The runtime templates define the statechart logic shared by every flavor of the header and body.
+	runtimeHeaderFilename embedPath = "runtime.h.tmpl"
+	runtimeBodyFilename   embedPath = "runtime.cpp.tmpl"
 )
 
 // templateManager is a helper struct to handle the common context for template loading.
@@ -27,53 +36,65 @@ type templateManager struct {
 	headerTemplate *template.Template
 	bodyTemplate   *template.Template
 
-	sc *ir.Statechart
+	context *templateContext
 }
 
-func newTemplateManager(sc *ir.Statechart) (*templateManager, error) {
-	headerTemplate, err := readTemplate(headerFilename)
+func newTemplateManager(sc *ir.Statechart, options *BackendOptions) (*templateManager, error) {
+	headerFile, bodyFile := headerFilename, bodyFilename
+	if options.Flavor == Flavor_Unreal {
+		headerFile, bodyFile = unrealHeaderFilename, unrealBodyFilename
+	}
+
+	headerTemplate, err := readTemplate(headerFile, runtimeHeaderFilename)
 	if err != nil {
 		return nil, fmt.Errorf("reading header template: %w", err)
 	}
 
-	bodyTemplate, err := readTemplate(bodyFilename)
+	bodyTemplate, err := readTemplate(bodyFile, runtimeBodyFilename)
 	if err != nil {
 		return nil, fmt.Errorf("reading body template: %w", err)
 	}
 
+	context, err := newTemplateContext(sc, options)
+	if err != nil {
+		return nil, fmt.Errorf("building template context: %w", err)
+	}
+
 	return &templateManager{
 		headerTemplate: headerTemplate,
 		bodyTemplate:   bodyTemplate,
-		sc:             sc,
+		context:        context,
 	}, nil
 }
 
-func readTemplate(ep embedPath) (*template.Template, error) {
+// readTemplate reads the template at |ep|, along with the |shared| ones it uses.
+func readTemplate(ep embedPath, shared ...embedPath) (*template.Template, error) {
 	// Load sprig functions.
 	epstr := string(ep)
-	tmpl, err := template.New(epstr).Funcs(sprig.FuncMap()).ParseFS(embeddedFS, epstr)
+	patterns := []string{epstr}
+	for _, path := range shared {
+		patterns = append(patterns, string(path))
+	}
+
+	tmpl, err := template.New(epstr).Funcs(sprig.FuncMap()).ParseFS(embeddedFS, patterns...)
 	if err != nil {
 		return nil, fmt.Errorf("reading embedded template %q: %w", epstr, err)
 	}
 	return tmpl, nil
 }
 
-func (tm *templateManager) generateBody(options *BackendOptions) (io.Reader, error) {
-	context := newTemplateContext(tm.sc, options)
-
+func (tm *templateManager) generateBody() (io.Reader, error) {
 	var buf bytes.Buffer
-	if err := tm.bodyTemplate.Execute(&buf, context); err != nil {
+	if err := tm.bodyTemplate.Execute(&buf, tm.context); err != nil {
 		return nil, fmt.Errorf("executing template: %w", err)
 	}
 
 	return &buf, nil
 }
 
-func (tm *templateManager) generateHeader(options *BackendOptions) (io.Reader, error) {
-	context := newTemplateContext(tm.sc, options)
-
+func (tm *templateManager) generateHeader() (io.Reader, error) {
 	var buf bytes.Buffer
-	if err := tm.headerTemplate.Execute(&buf, context); err != nil {
+	if err := tm.headerTemplate.Execute(&buf, tm.context); err != nil {
 		return nil, fmt.Errorf("executing template: %w", err)
 	}
 
@@ -88,16 +109,93 @@ type templateContext struct {
 	// Common Use strings.
 	ImplName      string
 	InterfaceName string
+
+	// Transitions holds the processed transitions, ready to be generated.
+	Transitions *backend.TransitionModel
+
+	// Unreal holds what the Unreal flavor generates on top of the runtime. nil for other flavors.
+	Unreal *unrealContext
+
+	mapType typeMapper
 }
 
-func newTemplateContext(sc *ir.Statechart, options *BackendOptions) *templateContext {
+func newTemplateContext(sc *ir.Statechart, options *BackendOptions) (*templateContext, error) {
 	tc := &templateContext{
 		BackendOptions: *options,
 		Statechart:     sc,
 
 		ImplName:      fmt.Sprintf("Statechart%sImpl", sc.Name),
 		InterfaceName: fmt.Sprintf("Statechart%s", sc.Name),
+
+		mapType: sameType,
+	}
+
+	// Unreal functions cannot be overloaded.
+	naming := backend.CallbackNaming_Overloads
+	if options.Flavor == Flavor_Unreal {
+		naming = backend.CallbackNaming_PerTrigger
+	}
+	tc.Transitions = backend.NewTransitionModel(sc, naming)
+
+	if options.Flavor == Flavor_Unreal {
+		tc.mapType = unrealType
+
+		// The generated.h include of Unreal is named after the header, so we need to know it.
+		if tc.HeaderInclude == "" {
+			tc.HeaderInclude = fmt.Sprintf("%sStatechart.h", sc.Name)
+		}
+
+		unreal, err := newUnrealContext(sc, tc.HeaderInclude, tc.Transitions)
+		if err != nil {
+			return nil, fmt.Errorf("unreal: %w", err)
+		}
+		tc.Unreal = unreal
+	}
+
+	return tc, nil
+}
+
+// ArgType returns the type of an argument in the generated code.
+func (tc *templateContext) ArgType(argType string) string {
+	return tc.mapType(argType)
+}
+
+// ArgsDeclaration returns the parameters of a function that takes the arguments of |trigger|.
+func (tc *templateContext) ArgsDeclaration(trigger *ir.Trigger) string {
+	return argsDeclaration(trigger, tc.mapType)
+}
+
+// Call returns the C++ call to |callback| over the owner, forwarding the trigger arguments.
+func (tc *templateContext) Call(callback *backend.Callback) string {
+	return callbackCall(callback)
+}
+
+// Declaration returns the declaration of the owner method for |callback|.
+func (tc *templateContext) Declaration(callback *backend.Callback) string {
+	return callbackDeclaration(callback, tc.mapType)
+}
+
+// ValueType returns the type in which an argument of type |argType| is stored in a trigger payload.
+// Payloads outlive the call that raised them while queued, so they hold their own copy: references
+// and the const of the value are dropped (eg. "const std::string&" is stored as "std::string").
+func (tc *templateContext) ValueType(argType string) string {
+	valueType := strings.TrimSpace(strings.TrimRight(strings.TrimSpace(tc.ArgType(argType)), "&"))
+
+	// The const of a pointer applies to what it points to, which we keep.
+	if !strings.HasSuffix(valueType, "*") {
+		valueType = strings.TrimSpace(strings.TrimPrefix(valueType, "const "))
 	}
+	return valueType
+}
 
-	return tc
+// QueueOverflowDescription describes what the generated trigger queue does when full, completing
+// "when full, it ...".
+func (tc *templateContext) QueueOverflowDescription() string {
+	switch tc.QueueOverflow {
+	case Overflow_DropNewest:
+		return "drops the new trigger"
+	case Overflow_DropOldest:
+		return "drops the oldest pending trigger"
+	}
+	return "asserts and drops the new trigger"
 }
@@ -0,0 +1,198 @@
+package cpp
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/cristiandonosoc/gochart/pkg/backend"
+	"github.com/cristiandonosoc/gochart/pkg/ir"
+)
+
+// The Unreal flavor generates the same runtime as the standard one, with Unreal Engine wrappers
+// around it so that the statechart can be used from gameplay code and Blueprints:
+//
+//   - The states and triggers are the UENUMs E<Name>State and E<Name>Trigger.
+//   - The payload of each trigger is the USTRUCT F<Name>Trigger<Trigger>, which the runtime queues.
+//   - U<Name>StatechartComponent is a UActorComponent that owns the statechart. It activates it on
+//     BeginPlay (unless disabled) and exposes every trigger as a BlueprintCallable function.
+//   - The enter/exit reactions of the states are BlueprintImplementableEvents (OnEnter<State>,
+//     OnExit<State>_<Trigger>, ...), and are also broadcast through the OnStateEntered and
+//     OnStateExited multicast delegates. States without reactions are not broadcast.
+//   - Guards and actions are BlueprintImplementableEvents too. Guards that are not implemented
+//     return false. Unreal functions cannot be overloaded, so the ones called from triggers with
+//     different arguments are named after the trigger (eg. ActionSpawnDust_Land).
+//
+// The header includes its ".generated.h", named after it as Unreal Header Tool expects. The argument
+// types of the triggers are mapped to the Unreal ones (see unrealTypes), as Blueprints only support
+// those. Types not in the table (eg. FVector or AActor*) are kept as written.
+
+// unrealTypes maps the C++ types of the trigger arguments to the Unreal equivalent.
+var unrealTypes = map[string]string{
+	"short":              "int16",
+	"unsigned short":     "uint16",
+	"int":                "int32",
+	"unsigned":           "uint32",
+	"unsigned int":       "uint32",
+	"long long":          "int64",
+	"unsigned long long": "uint64",
+	"unsigned char":      "uint8",
+	"int8_t":             "int8",
+	"uint8_t":            "uint8",
+	"int16_t":            "int16",
+	"uint16_t":           "uint16",
+	"int32_t":            "int32",
+	"uint32_t":           "uint32",
+	"int64_t":            "int64",
+	"uint64_t":           "uint64",
+	"std::string":        "FString",
+}
+
+// unrealType is the typeMapper of the Unreal flavor. The const and reference/pointer of the type
+// are kept (eg. "const std::string&" is "const FString&").
+func unrealType(argType string) string {
+	base := strings.TrimSpace(argType)
+
+	prefix := ""
+	if strings.HasPrefix(base, "const ") {
+		prefix = "const "
+		base = strings.TrimSpace(strings.TrimPrefix(base, "const "))
+	}
+
+	suffix := ""
+	for strings.HasSuffix(base, "&") || strings.HasSuffix(base, "*") {
+		suffix = base[len(base)-1:] + suffix
+		base = strings.TrimSpace(base[:len(base)-1])
+	}
+
+	mapped, ok := unrealTypes[base]
+	if !ok {
+		return argType
+	}
+	return prefix + mapped + suffix
+}
+
+// maxUnrealEnumValues is how many states or triggers fit in a uint8 enum, besides None.
+const maxUnrealEnumValues = 255
+
+// unrealContext has the names and callbacks the Unreal templates generate.
+type unrealContext struct {
+	ComponentName    string
+	StateEnum        string
+	TriggerEnum      string
+	DelegateName     string
+	GeneratedInclude string
+
+	// Category is the Blueprint category of the functions and properties of the component.
+	Category string
+
+	Reactions []*unrealReaction
+	Events    []*unrealEvent
+
+	statechartName string
+}
+
+// unrealReaction is an enter or exit callback of the runtime, which the component forwards to a
+// Blueprint event and to the state delegates.
+type unrealReaction struct {
+	State *ir.State
+	Enter bool
+
+	// Method is the callback the runtime calls over the component.
+	Method string
+	// Event is the Blueprint event it triggers.
+	Event string
+
+	// Params are the declaration of the trigger arguments, and Args how to forward them.
+	Params string
+	Args   string
+}
+
+// unrealEvent is a guard or action, which the runtime calls directly as a Blueprint event.
+type unrealEvent struct {
+	Return string
+	Name   string
+	Params string
+}
+
+// newUnrealContext builds the Unreal wrappers of |sc|. |transitions| has to use
+// CallbackNaming_PerTrigger, as Unreal functions cannot be overloaded.
+func newUnrealContext(sc *ir.Statechart, headerInclude string,
+	transitions *backend.TransitionModel) (*unrealContext, error) {
+	uc := &unrealContext{
+		ComponentName:    fmt.Sprintf("U%sStatechartComponent", sc.Name),
+		StateEnum:        fmt.Sprintf("E%sState", sc.Name),
+		TriggerEnum:      fmt.Sprintf("E%sTrigger", sc.Name),
+		DelegateName:     fmt.Sprintf("F%sStateSignature", sc.Name),
+		GeneratedInclude: strings.TrimSuffix(headerInclude, ".h") + ".generated.h",
+		Category:         fmt.Sprintf("Gochart|%s", sc.Name),
+		statechartName:   sc.Name,
+	}
+
+	// Blueprint enums are uint8, and we need room for None.
+	if len(sc.States) > maxUnrealEnumValues {
+		return nil, fmt.Errorf("%d states, but Blueprint enums hold at most %d", len(sc.States), maxUnrealEnumValues)
+	}
+	if len(sc.Triggers) > maxUnrealEnumValues {
+		return nil, fmt.Errorf("%d triggers, but Blueprint enums hold at most %d", len(sc.Triggers), maxUnrealEnumValues)
+	}
+
+	// The guards and actions are called directly over the component, with the per trigger names.
+	for _, method := range transitions.OwnerMethods {
+		if method.Kind != backend.Callback_Guard && method.Kind != backend.Callback_Action {
+			continue
+		}
+
+		uc.Events = append(uc.Events, &unrealEvent{
+			Return: callbackReturn(method),
+			Name:   method.Name,
+			Params: argsDeclaration(method.Trigger, unrealType),
+		})
+	}
+
+	ordered := sc.DocumentOrder()
+	for _, state := range ordered {
+		if state.DefaultEnter {
+			uc.Reactions = append(uc.Reactions, newUnrealReaction(state, true, nil))
+		}
+		for _, reaction := range state.EnterReactions {
+			uc.Reactions = append(uc.Reactions, newUnrealReaction(state, true, reaction.Trigger))
+		}
+
+		if state.DefaultExit {
+			uc.Reactions = append(uc.Reactions, newUnrealReaction(state, false, nil))
+		}
+		for _, reaction := range state.ExitReactions {
+			uc.Reactions = append(uc.Reactions, newUnrealReaction(state, false, reaction.Trigger))
+		}
+	}
+
+	return uc, nil
+}
+
+// newUnrealReaction returns the reaction of |state|. A nil trigger is the default reaction.
+func newUnrealReaction(state *ir.State, enter bool, trigger *ir.Trigger) *unrealReaction {
+	kind := "Exit"
+	if enter {
+		kind = "Enter"
+	}
+
+	reaction := &unrealReaction{
+		State:  state,
+		Enter:  enter,
+		Method: fmt.Sprintf("State%s_On%s", state.Name, kind),
+		Event:  fmt.Sprintf("On%s%s", kind, state.Name),
+		Params: argsDeclaration(trigger, unrealType),
+	}
+	if trigger != nil {
+		reaction.Method += "_" + trigger.Name
+		reaction.Event += "_" + trigger.Name
+		reaction.Args = strings.Join(trigger.ArgsNameList(), ", ")
+	}
+
+	return reaction
+}
+
+// PayloadName returns the name of the USTRUCT that holds the arguments of |trigger|.
+func (uc *unrealContext) PayloadName(trigger *ir.Trigger) string {
+	return fmt.Sprintf("F%sTrigger%s", uc.statechartName, trigger.Name)
+}
@@ -0,0 +1,244 @@
+package cpp
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/cristiandonosoc/gochart/pkg/backend"
+	"github.com/cristiandonosoc/gochart/pkg/frontend/gochart_lang"
+	"github.com/cristiandonosoc/gochart/pkg/ir"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func withUnreal(o *BackendOptions) {
+	o.Flavor = Flavor_Unreal
+}
+
+func TestGenerateUnrealGolden(t *testing.T) {
+	for _, name := range chartNames(t) {
+		t.Run(name, func(t *testing.T) {
+			header, body := generate(t, readStatechart(t, name), name, withUnreal)
+			checkGolden(t, filepath.Join("testdata", name+".unreal.h.golden"), header)
+			checkGolden(t, filepath.Join("testdata", name+".unreal.cpp.golden"), body)
+		})
+	}
+}
+
+// unrealStubs are the minimum of the engine the generated code needs to compile outside of Unreal.
+// The reflection macros expand to nothing, as Unreal Header Tool is the one that reads them.
+var unrealStubs = map[string]string{
+	"CoreMinimal.h": `#pragma once
+
+#include <cstdint>
+#include <functional>
+#include <string>
+
+#define UENUM(...)
+#define UMETA(...)
+#define USTRUCT(...)
+#define UCLASS(...)
+#define UPROPERTY(...)
+#define UFUNCTION(...)
+
+class UActorComponent;
+#define GENERATED_BODY() \
+public:                   \
+	using Super = UActorComponent;
+
+using int8 = std::int8_t;
+using uint8 = std::uint8_t;
+using int16 = std::int16_t;
+using uint16 = std::uint16_t;
+using int32 = std::int32_t;
+using uint32 = std::uint32_t;
+using int64 = std::int64_t;
+using uint64 = std::uint64_t;
+using FString = std::string;
+
+#define DECLARE_DYNAMIC_MULTICAST_DELEGATE_OneParam(Name, ParamType, ParamName) \
+	struct Name                                                                 \
+	{                                                                           \
+		std::function<void(ParamType)> Callback;                                \
+		void Broadcast(ParamType ParamName) const                               \
+		{                                                                       \
+			if (Callback) {                                                     \
+				Callback(ParamName);                                            \
+			}                                                                   \
+		}                                                                       \
+	};
+`,
+	"Components/ActorComponent.h": `#pragma once
+
+namespace EEndPlayReason
+{
+enum Type
+{
+	Destroyed,
+};
+}
+
+struct FActorComponentTickFunction
+{
+	bool bCanEverTick = true;
+};
+
+class UActorComponent
+{
+public:
+	virtual ~UActorComponent() = default;
+
+	virtual void BeginPlay() {}
+	virtual void EndPlay(const EEndPlayReason::Type EndPlayReason) {}
+
+protected:
+	FActorComponentTickFunction PrimaryComponentTick;
+};
+`,
+}
+
+// TestUnrealCompileAndRun drives the generated component as TestCompileAndRun does with the
+// standard flavor, over stubs of the engine.
+func TestUnrealCompileAndRun(t *testing.T) {
+	compiler := findCompiler(t)
+
+	for _, name := range chartNames(t) {
+		t.Run(name, func(t *testing.T) {
+			sc := readStatechart(t, name)
+			header, body := generate(t, sc, name, withUnreal)
+
+			uc, err := newUnrealContext(sc, name+".h", backend.NewTransitionModel(sc, backend.CallbackNaming_PerTrigger))
+			require.NoError(t, err)
+
+			files := map[string]string{
+				name + ".h":           header,
+				name + ".cpp":         body,
+				name + ".generated.h": "#pragma once\n",
+				"main.cpp":            unrealDriver(sc, uc, name),
+			}
+			for filename, content := range unrealStubs {
+				files[filename] = content
+			}
+
+			out := compileAndRun(t, compiler, files)
+			checkGolden(t, filepath.Join("testdata", name+".unreal.run.golden"), out)
+		})
+	}
+}
+
+// unrealDriver returns a main.cpp that drives the component generated for |sc|. It defines the
+// Blueprint events, as Unreal Header Tool would, logging every call and letting every guard pass.
+func unrealDriver(sc *ir.Statechart, uc *unrealContext, name string) string {
+	var sb strings.Builder
+
+	implName := fmt.Sprintf("gochart::Statechart%sImpl", sc.Name)
+
+	fmt.Fprintf(&sb, "#include \"%s.h\"\n\n#include <cstdio>\n\n", name)
+
+	for _, reaction := range uc.Reactions {
+		fmt.Fprintf(&sb, "void %s::%s(%s)\n{\n", uc.ComponentName, reaction.Event, reaction.Params)
+		fmt.Fprintf(&sb, "\tstd::printf(\"  %s\\n\");\n}\n\n", reaction.Event)
+	}
+	for _, event := range uc.Events {
+		fmt.Fprintf(&sb, "%s %s::%s(%s)\n{\n", event.Return, uc.ComponentName, event.Name, event.Params)
+		fmt.Fprintf(&sb, "\tstd::printf(\"  %s\\n\");\n", event.Name)
+		if event.Return == "bool" {
+			sb.WriteString("\treturn true;\n")
+		}
+		sb.WriteString("}\n\n")
+	}
+
+	fmt.Fprintf(&sb, "static void PrintActive(const %s& component)\n{\n", uc.ComponentName)
+	sb.WriteString("\tstd::printf(\"active:\");\n")
+	fmt.Fprintf(&sb, "\tfor (std::size_t i = 0; i < %s::kStateCount; i++) {\n", implName)
+	fmt.Fprintf(&sb, "\t\tauto state = static_cast<%s>(i);\n", uc.StateEnum)
+	sb.WriteString("\t\tif (component.IsStateActive(state)) {\n")
+	fmt.Fprintf(&sb, "\t\t\tstd::printf(\" %%s\", %s::ToString(state));\n", implName)
+	sb.WriteString("\t\t}\n\t}\n\tstd::printf(\"\\n\");\n}\n\n")
+
+	var triggers []string
+	for _, trigger := range sc.Triggers {
+		args := make([]string, len(trigger.Args))
+		for i := range args {
+			args[i] = "{}"
+		}
+		triggers = append(triggers, fmt.Sprintf("Trigger%s(%s)", trigger.Name, strings.Join(args, ", ")))
+	}
+
+	sb.WriteString("int main()\n{\n")
+	fmt.Fprintf(&sb, "\t%s component;\n", uc.ComponentName)
+	fmt.Fprintf(&sb, "\tcomponent.OnStateEntered.Callback = [](%s state) { std::printf(\"  entered %%s\\n\", %s::ToString(state)); };\n", uc.StateEnum, implName)
+	fmt.Fprintf(&sb, "\tcomponent.OnStateExited.Callback = [](%s state) { std::printf(\"  exited %%s\\n\", %s::ToString(state)); };\n\n", uc.StateEnum, implName)
+	sb.WriteString("\tstd::printf(\"BeginPlay\\n\");\n\tcomponent.BeginPlay();\n\tPrintActive(component);\n")
+	sb.WriteString("\n\tfor (int round = 0; round < 2; round++) {")
+	for _, trigger := range triggers {
+		fmt.Fprintf(&sb, "\n\t\tstd::printf(\"%s\\n\");\n", strings.SplitN(trigger, "(", 2)[0])
+		fmt.Fprintf(&sb, "\t\tcomponent.%s;\n\t\tPrintActive(component);\n", trigger)
+	}
+	sb.WriteString("\t}\n")
+	sb.WriteString("\n\tstd::printf(\"EndPlay\\n\");\n\tcomponent.EndPlay(EEndPlayReason::Destroyed);\n\tPrintActive(component);\n")
+
+	// Once deactivated, triggers are ignored.
+	for _, trigger := range triggers {
+		fmt.Fprintf(&sb, "\tcomponent.%s;\n", trigger)
+	}
+	sb.WriteString("\tPrintActive(component);\n")
+	sb.WriteString("\treturn 0;\n}\n")
+
+	return sb.String()
+}
+
+func TestUnrealType(t *testing.T) {
+	testcases := []struct {
+		argType string
+		want    string
+	}{
+		{"int", "int32"},
+		{"uint8_t", "uint8"},
+		{"unsigned long long", "uint64"},
+		{"const std::string&", "const FString&"},
+		{"std::string *", "FString*"},
+		{"FVector", "FVector"},
+		{"const AActor*", "const AActor*"},
+	}
+
+	for _, testcase := range testcases {
+		assert.Equal(t, testcase.want, unrealType(testcase.argType), testcase.argType)
+	}
+}
+
+func TestUnrealOverloads(t *testing.T) {
+	input := `
+statechart Door {
+	trigger Open("int code")
+	trigger Kick
+
+	state Closed {
+		initial
+		transition Opened { trigger Open action Log }
+		transition Opened { trigger Kick action Log }
+	}
+
+	state Opened {}
+}`
+
+	scdata, err := gochart_lang.NewGochartLangFrontend().Process(strings.NewReader(input))
+	require.NoError(t, err)
+	sc, err := ir.ProcessStatechartData(scdata)
+	require.NoError(t, err)
+
+	// The standard flavor overloads the action.
+	header, _ := generate(t, sc, "door")
+	assert.Contains(t, header, "void ActionLog(int code);")
+	assert.Contains(t, header, "void ActionLog();")
+
+	// Unreal functions cannot be overloaded, so the Unreal flavor names them after their trigger.
+	header, body := generate(t, sc, "door", withUnreal)
+	assert.Contains(t, header, "void ActionLog_Open(int32 code);")
+	assert.Contains(t, header, "void ActionLog_Kick();")
+	assert.Contains(t, header, "Owner->ActionLog_Open(trigger.code);")
+	assert.NotContains(t, header+body, "ActionLog(")
+}
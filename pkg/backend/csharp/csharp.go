@@ -0,0 +1,159 @@
+// csharp is a Gochart backend meant to generate a C# implementation of a statechart, so that the
+// same statechart can be used in Unity or .NET tooling.
+//
+// For a statechart named Game, the generated file has:
+//
+//   - GameState, an enum of the states.
+//   - IGameOwner, the interface with the enter/exit reactions, guards and actions that the owner of
+//     the statechart implements. The methods are named as the ones the C++ backend calls.
+//   - GameStatechart, a partial class that runs the statechart, with a Trigger<Trigger> method per
+//     trigger. The arguments are declared with C++ types, which get mapped to C# (see
+//     csharpTypes). Triggers are processed run-to-completion, as with the C++ backend: the ones
+//     raised while another one is being processed are queued.
+package csharp
+
+import (
+	"bytes"
+	_ "embed"
+	"fmt"
+	"io"
+	"text/template"
+	"time"
+
+	"github.com/Masterminds/sprig/v3"
+
+	"github.com/cristiandonosoc/gochart/pkg/backend"
+	"github.com/cristiandonosoc/gochart/pkg/ir"
+)
+
+var _ backend.GochartDocumentBackend = (*csharpGochartBackend)(nil)
+
+//go:embed statechart.cs.tmpl
+var statechartTemplate string
+
+type csharpGochartBackend struct {
+	options *BackendOptions
+}
+
+type BackendOptions struct {
+	Time    time.Time
+	Version string
+
+	// Namespace is the namespace of the generated code.
+	Namespace string
+
+	// Types maps C++ types to C# ones, on top of (and over) the built-in mappings.
+	Types map[string]string
+}
+
+type Option func(*BackendOptions)
+
+func NewCSharpGochartBackend(opts ...Option) *csharpGochartBackend {
+	options := &BackendOptions{
+		Version:   "DEVELOPMENT",
+		Time:      time.Now(),
+		Namespace: "Gochart",
+	}
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	return &csharpGochartBackend{
+		options: options,
+	}
+}
+
+func (csharp *csharpGochartBackend) Generate(sc *ir.Statechart) (io.Reader, error) {
+	context, err := newTemplateContext(sc, csharp.options)
+	if err != nil {
+		return nil, fmt.Errorf("building template context: %w", err)
+	}
+
+	tmpl, err := template.New("statechart.cs").Funcs(sprig.FuncMap()).Parse(statechartTemplate)
+	if err != nil {
+		return nil, fmt.Errorf("parsing template: %w", err)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, context); err != nil {
+		return nil, fmt.Errorf("executing template: %w", err)
+	}
+
+	return &buf, nil
+}
+
+// templateContext has the information needed by the template.
+type templateContext struct {
+	BackendOptions
+	Statechart *ir.Statechart
+
+	// Names of the generated types.
+	StateType      string
+	StatechartType string
+	OwnerType      string
+
+	Triggers    []*csTrigger
+	Transitions *backend.TransitionModel
+
+	triggerMap map[*ir.Trigger]*csTrigger
+}
+
+func newTemplateContext(sc *ir.Statechart, options *BackendOptions) (*templateContext, error) {
+	tc := &templateContext{
+		BackendOptions: *options,
+		Statechart:     sc,
+
+		StateType:      fmt.Sprintf("%sState", sc.Name),
+		StatechartType: fmt.Sprintf("%sStatechart", sc.Name),
+		OwnerType:      fmt.Sprintf("I%sOwner", sc.Name),
+	}
+
+	triggers, err := newCsTriggers(sc, &typeMapper{extra: options.Types})
+	if err != nil {
+		return nil, err
+	}
+	tc.Triggers = triggers
+
+	tc.triggerMap = make(map[*ir.Trigger]*csTrigger)
+	for _, trigger := range triggers {
+		tc.triggerMap[trigger.Trigger] = trigger
+	}
+
+	tc.Transitions = backend.NewTransitionModel(sc, backend.CallbackNaming_Overloads)
+
+	return tc, nil
+}
+
+// StateIndex returns the expression of the index of |state| in the arrays of states.
+func (tc *templateContext) StateIndex(state *ir.State) string {
+	return fmt.Sprintf("(int)%s", tc.StateConst(state))
+}
+
+// StateConst returns the value of |state| in the state enum. nil is the top level of the
+// statechart, which is the None value.
+func (tc *templateContext) StateConst(state *ir.State) string {
+	if state == nil {
+		return tc.StateType + ".None"
+	}
+	return fmt.Sprintf("%s.%s", tc.StateType, state.Name)
+}
+
+// Params returns the parameters of a method that takes the arguments of |trigger|.
+func (tc *templateContext) Params(trigger *ir.Trigger) string {
+	return tc.triggerMap[trigger].Declaration()
+}
+
+// Args returns the arguments to forward the parameters of |trigger|.
+func (tc *templateContext) Args(trigger *ir.Trigger) string {
+	return tc.triggerMap[trigger].CallArgs()
+}
+
+// Call returns the call to |callback| over the owner, forwarding the trigger parameters.
+func (tc *templateContext) Call(callback *backend.Callback) string {
+	return callbackCall(callback, tc.triggerMap[callback.Trigger])
+}
+
+// Declaration returns the declaration of |callback| in the owner interface.
+func (tc *templateContext) Declaration(callback *backend.Callback) string {
+	return callbackDeclaration(callback, tc.triggerMap[callback.Trigger])
+}
@@ -0,0 +1,264 @@
+package csharp
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cristiandonosoc/gochart/pkg/frontend/gochart_lang"
+	"github.com/cristiandonosoc/gochart/pkg/frontend/yaml"
+	"github.com/cristiandonosoc/gochart/pkg/ir"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// The tests run the backend over the statecharts in pkg/ir/testdata. The generated code is compared
+// against the golden files in testdata, which are rewritten with:
+//
+//	go test ./pkg/backend/csharp -update
+var update = flag.Bool("update", false, "update the golden files")
+
+const chartsDir = "../../ir/testdata"
+
+func chartNames(t *testing.T) []string {
+	t.Helper()
+
+	paths, err := filepath.Glob(filepath.Join(chartsDir, "*.yaml"))
+	require.NoError(t, err)
+	require.NotEmpty(t, paths)
+
+	var names []string
+	for _, path := range paths {
+		names = append(names, strings.TrimSuffix(filepath.Base(path), ".yaml"))
+	}
+	return names
+}
+
+func readStatechart(t *testing.T, name string) *ir.Statechart {
+	t.Helper()
+
+	scdata, err := yaml.NewYamlFrontend().ProcessFromFile(filepath.Join(chartsDir, name+".yaml"))
+	require.NoError(t, err)
+
+	sc, err := ir.ProcessStatechartData(scdata)
+	require.NoError(t, err)
+
+	return sc
+}
+
+func generate(t *testing.T, sc *ir.Statechart, opts ...Option) string {
+	t.Helper()
+
+	opts = append([]Option{func(o *BackendOptions) {
+		o.Version = "TEST"
+		o.Time = time.Date(2023, time.July, 4, 0, 0, 0, 0, time.UTC)
+	}}, opts...)
+	r, err := NewCSharpGochartBackend(opts...).Generate(sc)
+	require.NoError(t, err)
+
+	data, err := io.ReadAll(r)
+	require.NoError(t, err)
+
+	return string(data)
+}
+
+func TestGenerateGolden(t *testing.T) {
+	for _, name := range chartNames(t) {
+		t.Run(name, func(t *testing.T) {
+			got := generate(t, readStatechart(t, name))
+			path := filepath.Join("testdata", name+".cs.golden")
+
+			if *update {
+				require.NoError(t, os.WriteFile(path, []byte(got), 0644))
+				return
+			}
+
+			want, err := os.ReadFile(path)
+			require.NoError(t, err, "run with -update to create the golden files")
+			assert.Equal(t, string(want), got, "run with -update if the change is intended")
+		})
+	}
+}
+
+const driverProject = `<Project Sdk="Microsoft.NET.Sdk">
+  <PropertyGroup>
+    <OutputType>Exe</OutputType>
+    <TargetFramework>net8.0</TargetFramework>
+    <Nullable>disable</Nullable>
+    <TreatWarningsAsErrors>true</TreatWarningsAsErrors>
+  </PropertyGroup>
+</Project>
+`
+
+// TestRun builds the generated code of every statechart, each along with an owner that logs every
+// callback, and runs them as the C++ backend tests do. Both have to behave the same, so the output
+// is compared against the run golden files of the C++ backend.
+func TestRun(t *testing.T) {
+	dotnet, err := exec.LookPath("dotnet")
+	if err != nil {
+		t.Skip("no dotnet found")
+	}
+
+	// Building is slow, so a single program runs the statechart named by its argument.
+	names := chartNames(t)
+	dir := t.TempDir()
+	files := map[string]string{
+		"driver.csproj": driverProject,
+		"Program.cs":    program(names),
+	}
+	for _, name := range names {
+		namespace := "Test_" + name
+		sc := readStatechart(t, name)
+		files[name+".cs"] = generate(t, sc, func(o *BackendOptions) { o.Namespace = namespace })
+
+		context, err := newTemplateContext(sc, &BackendOptions{Namespace: namespace})
+		require.NoError(t, err)
+		files[name+"_driver.cs"] = driver(context)
+	}
+	for filename, content := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, filename), []byte(content), 0644))
+	}
+
+	env := append(os.Environ(), "DOTNET_CLI_TELEMETRY_OPTOUT=1", "DOTNET_NOLOGO=1")
+	build := exec.Command(dotnet, "build", "-o", "out")
+	build.Dir = dir
+	build.Env = env
+	out, err := build.CombinedOutput()
+	require.NoError(t, err, "building:\n%s", out)
+
+	for _, name := range names {
+		t.Run(name, func(t *testing.T) {
+			run := exec.Command(dotnet, filepath.Join("out", "driver.dll"), name)
+			run.Dir = dir
+			run.Env = env
+			out, err := run.CombinedOutput()
+			require.NoError(t, err, "running:\n%s", out)
+
+			want, err := os.ReadFile(filepath.Join("..", "cpp", "testdata", name+".run.golden"))
+			require.NoError(t, err)
+			assert.Equal(t, string(want), string(out))
+		})
+	}
+}
+
+// program returns the entry point of the driver, which runs the statechart named by its argument.
+func program(names []string) string {
+	var sb strings.Builder
+
+	sb.WriteString("public static class Program\n{\n")
+	sb.WriteString("    public static void Main(string[] args)\n    {\n")
+	sb.WriteString("        switch (args[0])\n        {\n")
+	for _, name := range names {
+		fmt.Fprintf(&sb, "            case %q: Test_%s.Driver.Run(); break;\n", name, name)
+	}
+	sb.WriteString("        }\n    }\n}\n")
+
+	return sb.String()
+}
+
+// driver returns the code that drives the generated statechart. The owner logs every callback and
+// lets every guard pass.
+func driver(tc *templateContext) string {
+	var sb strings.Builder
+
+	fmt.Fprintf(&sb, "using System;\n\nnamespace %s\n{\n", tc.Namespace)
+
+	fmt.Fprintf(&sb, "    public class Owner : %s\n    {\n", tc.OwnerType)
+	for _, method := range tc.Transitions.OwnerMethods {
+		ret, methodName, params := callbackReturn(method), method.Name, ""
+		if method.Trigger != nil {
+			params = tc.Params(method.Trigger)
+		}
+		fmt.Fprintf(&sb, "        public %s %s(%s)\n        {\n", ret, methodName, params)
+		fmt.Fprintf(&sb, "            Console.WriteLine(\"  %s\");\n", methodName)
+		if ret == "bool" {
+			sb.WriteString("            return true;\n")
+		}
+		sb.WriteString("        }\n\n")
+	}
+	sb.WriteString("    }\n\n")
+
+	sb.WriteString("    public static class Driver\n    {\n")
+	fmt.Fprintf(&sb, "        private static void PrintActive(%s sc)\n        {\n", tc.StatechartType)
+	sb.WriteString("            Console.Write(\"active:\");\n")
+	fmt.Fprintf(&sb, "            for (int i = 0; i < %s.StateCount; i++)\n            {\n", tc.StatechartType)
+	fmt.Fprintf(&sb, "                if (sc.IsActive((%s)i))\n                {\n", tc.StateType)
+	fmt.Fprintf(&sb, "                    Console.Write(\" \" + (%s)i);\n", tc.StateType)
+	sb.WriteString("                }\n            }\n            Console.WriteLine();\n        }\n\n")
+
+	sb.WriteString("        public static void Run()\n        {\n")
+	fmt.Fprintf(&sb, "            var sc = new %s(new Owner());\n\n", tc.StatechartType)
+	sb.WriteString("            Console.WriteLine(\"Activate\");\n            sc.Activate();\n            PrintActive(sc);\n\n")
+	sb.WriteString("            for (int round = 0; round < 2; round++)\n            {")
+	for _, trigger := range tc.Triggers {
+		args := make([]string, len(trigger.Params))
+		for i, param := range trigger.Params {
+			args[i] = fmt.Sprintf("default(%s)", param.Type)
+		}
+		fmt.Fprintf(&sb, "\n                Console.WriteLine(\"Trigger%s\");\n", trigger.Trigger.Name)
+		fmt.Fprintf(&sb, "                sc.Trigger%s(%s);\n                PrintActive(sc);\n", trigger.Trigger.Name, strings.Join(args, ", "))
+	}
+	sb.WriteString("            }\n\n")
+	sb.WriteString("            Console.WriteLine(\"Deactivate\");\n            sc.Deactivate();\n            PrintActive(sc);\n")
+	sb.WriteString("        }\n    }\n}\n")
+
+	return sb.String()
+}
+
+func TestCSharpType(t *testing.T) {
+	testcases := []struct {
+		cppType string
+		want    string
+	}{
+		{"int", "int"},
+		{"std::uint8_t", "byte"},
+		{"unsigned long long", "ulong"},
+		{"bool", "bool"},
+		{"float", "float"},
+		{"const std::string&", "string"},
+		{"const char*", "string"},
+		{"const Vector3&", "Vector3"},
+		{"GameObject*", "GameObject"},
+		{"std::vector<int>", "List<int>"},
+		{"const std::map<std::string, std::vector<float>>&", "Dictionary<string, List<float>>"},
+	}
+
+	types := &typeMapper{extra: map[string]string{
+		"Vector3":    "UnityEngine.Vector3",
+		"GameObject": "UnityEngine.GameObject",
+	}}
+	for _, testcase := range testcases {
+		got, err := types.csharpType(testcase.cppType)
+		require.NoError(t, err, testcase.cppType)
+		assert.Equal(t, strings.NewReplacer("Vector3", "UnityEngine.Vector3", "GameObject", "UnityEngine.GameObject").Replace(testcase.want), got, testcase.cppType)
+	}
+
+	_, err := types.csharpType("FVector")
+	assert.ErrorContains(t, err, `no C# type for C++ type "FVector"`)
+}
+
+func TestKeywordArguments(t *testing.T) {
+	input := `
+statechart Door {
+	trigger Open("int event, const std::string& object")
+	state Closed { initial transition Opened { trigger Open action Log } }
+	state Opened {}
+}`
+
+	scdata, err := gochart_lang.NewGochartLangFrontend().Process(strings.NewReader(input))
+	require.NoError(t, err)
+	sc, err := ir.ProcessStatechartData(scdata)
+	require.NoError(t, err)
+
+	code := generate(t, sc)
+	assert.Contains(t, code, "public void TriggerOpen(int @event, string @object)")
+	assert.Contains(t, code, "void ActionLog(int @event, string @object);")
+	assert.Contains(t, code, "_owner.ActionLog(@event, @object);")
+}
@@ -0,0 +1,93 @@
+package csharp
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/cristiandonosoc/gochart/pkg/backend"
+	"github.com/cristiandonosoc/gochart/pkg/ir"
+)
+
+// The generated C# code follows the transition model shared with the other backends (see
+// backend.TransitionModel), calling the same methods over its owner. C# supports overloading, so the
+// guards and actions keep their plain names. The trigger arguments are forwarded as parameters from
+// the trigger method down to the owner callbacks.
+
+// csTrigger is a trigger along with the C# parameters of its arguments.
+type csTrigger struct {
+	Trigger *ir.Trigger
+	Params  []*csParam
+}
+
+type csParam struct {
+	Name string
+	Type string
+}
+
+// Declaration returns the parameters of a method that takes the arguments of the trigger.
+func (ct *csTrigger) Declaration() string {
+	params := make([]string, 0, len(ct.Params))
+	for _, param := range ct.Params {
+		params = append(params, fmt.Sprintf("%s %s", param.Type, param.Name))
+	}
+	return strings.Join(params, ", ")
+}
+
+// CallArgs returns the arguments to forward the parameters of the trigger.
+func (ct *csTrigger) CallArgs() string {
+	args := make([]string, 0, len(ct.Params))
+	for _, param := range ct.Params {
+		args = append(args, param.Name)
+	}
+	return strings.Join(args, ", ")
+}
+
+func newCsTriggers(sc *ir.Statechart, types *typeMapper) ([]*csTrigger, error) {
+	var triggers []*csTrigger
+	for _, trigger := range sc.Triggers {
+		ct := &csTrigger{
+			Trigger: trigger,
+		}
+
+		for _, arg := range trigger.Args {
+			csharpType, err := types.csharpType(arg.Type)
+			if err != nil {
+				return nil, fmt.Errorf("trigger %q, argument %q: %w", trigger.Name, arg.Name, err)
+			}
+
+			ct.Params = append(ct.Params, &csParam{
+				Name: paramName(arg.Name),
+				Type: csharpType,
+			})
+		}
+
+		triggers = append(triggers, ct)
+	}
+	return triggers, nil
+}
+
+// callbackCall returns the call to |callback|, forwarding the parameters of |trigger|.
+func callbackCall(callback *backend.Callback, trigger *csTrigger) string {
+	args := ""
+	if trigger != nil {
+		args = trigger.CallArgs()
+	}
+	return fmt.Sprintf("%s(%s)", callback.Name, args)
+}
+
+// callbackReturn returns the C# return type of |callback|.
+func callbackReturn(callback *backend.Callback) string {
+	if callback.IsGuard() {
+		return "bool"
+	}
+	return "void"
+}
+
+// callbackDeclaration returns the declaration of |callback| in the owner interface.
+func callbackDeclaration(callback *backend.Callback, trigger *csTrigger) string {
+	params := ""
+	if trigger != nil {
+		params = trigger.Declaration()
+	}
+	return fmt.Sprintf("%s %s(%s);", callbackReturn(callback), callback.Name, params)
+}
@@ -0,0 +1,124 @@
+package csharp
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/cristiandonosoc/gochart/pkg/backend"
+)
+
+// The trigger arguments are declared with C++ types, which we translate to C# ones. Values are
+// passed as values: a "const std::string&" is a string. Pointers become the type they point to, as
+// in C# that would be a reference type, and the standard containers map to the ones of
+// System.Collections.Generic (eg. "std::vector<int>" is a List<int>).
+
+// csharpTypes maps the C++ types of the trigger arguments to C# ones. The fixed width integer types
+// are also accepted with the "std::" prefix.
+var csharpTypes = map[string]string{
+	"bool":               "bool",
+	"char":               "byte",
+	"signed char":        "sbyte",
+	"unsigned char":      "byte",
+	"short":              "short",
+	"unsigned short":     "ushort",
+	"int":                "int",
+	"unsigned":           "uint",
+	"unsigned int":       "uint",
+	"long":               "long",
+	"unsigned long":      "ulong",
+	"long long":          "long",
+	"unsigned long long": "ulong",
+	"int8_t":             "sbyte",
+	"uint8_t":            "byte",
+	"int16_t":            "short",
+	"uint16_t":           "ushort",
+	"int32_t":            "int",
+	"uint32_t":           "uint",
+	"int64_t":            "long",
+	"uint64_t":           "ulong",
+	"size_t":             "ulong",
+	"float":              "float",
+	"double":             "double",
+	"std::string":        "string",
+	"std::string_view":   "string",
+	"const char*":        "string",
+}
+
+// typeMapper translates C++ types into C# ones, with the extra mappings of the options taking
+// precedence over csharpTypes.
+type typeMapper struct {
+	extra map[string]string
+}
+
+func (tm *typeMapper) csharpType(cppType string) (string, error) {
+	cppType = strings.TrimSpace(cppType)
+
+	// Strings are the only pointers that do not map to the type they point to.
+	if cppType == "const char*" || cppType == "const char *" {
+		return "string", nil
+	}
+
+	// References, pointers and the const of values do not matter once passed by value or reference.
+	base := strings.TrimSpace(strings.TrimRight(cppType, "&*"))
+	base = strings.TrimSpace(strings.TrimPrefix(base, "const "))
+
+	if csharpType, ok := tm.extra[base]; ok {
+		return csharpType, nil
+	}
+	if csharpType, ok := csharpTypes[strings.TrimPrefix(base, "std::")]; ok && strings.HasSuffix(base, "_t") {
+		return csharpType, nil
+	}
+	if csharpType, ok := csharpTypes[base]; ok {
+		return csharpType, nil
+	}
+
+	if name, args, ok := backend.SplitTemplate(base); ok {
+		var generic string
+		switch {
+		case name == "std::vector" && len(args) == 1:
+			generic = "List"
+		case (name == "std::map" || name == "std::unordered_map") && len(args) == 2:
+			generic = "Dictionary"
+		}
+
+		if generic != "" {
+			mapped := make([]string, 0, len(args))
+			for _, arg := range args {
+				csharpType, err := tm.csharpType(arg)
+				if err != nil {
+					return "", err
+				}
+				mapped = append(mapped, csharpType)
+			}
+			return fmt.Sprintf("%s<%s>", generic, strings.Join(mapped, ", ")), nil
+		}
+	}
+
+	return "", fmt.Errorf("no C# type for C++ type %q", cppType)
+}
+
+// csharpKeywords are the reserved keywords of C#, which cannot be used as parameter names.
+var csharpKeywords = map[string]bool{
+	"abstract": true, "as": true, "base": true, "bool": true, "break": true, "byte": true,
+	"case": true, "catch": true, "char": true, "checked": true, "class": true, "const": true,
+	"continue": true, "decimal": true, "default": true, "delegate": true, "do": true,
+	"double": true, "else": true, "enum": true, "event": true, "explicit": true, "extern": true,
+	"false": true, "finally": true, "fixed": true, "float": true, "for": true, "foreach": true,
+	"goto": true, "if": true, "implicit": true, "in": true, "int": true, "interface": true,
+	"internal": true, "is": true, "lock": true, "long": true, "namespace": true, "new": true,
+	"null": true, "object": true, "operator": true, "out": true, "override": true, "params": true,
+	"private": true, "protected": true, "public": true, "readonly": true, "ref": true,
+	"return": true, "sbyte": true, "sealed": true, "short": true, "sizeof": true,
+	"stackalloc": true, "static": true, "string": true, "struct": true, "switch": true,
+	"this": true, "throw": true, "true": true, "try": true, "typeof": true, "uint": true,
+	"ulong": true, "unchecked": true, "unsafe": true, "ushort": true, "using": true,
+	"virtual": true, "void": true, "volatile": true, "while": true,
+}
+
+// paramName returns the name of a parameter for an argument named |name|, escaping C# keywords.
+func paramName(name string) string {
+	if csharpKeywords[name] {
+		return "@" + name
+	}
+	return name
+}
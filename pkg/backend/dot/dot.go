@@ -0,0 +1,71 @@
+// dot is a Gochart backend meant to render a statechart as a Graphviz DOT graph.
+package dot
+
+import (
+	"bytes"
+	"io"
+	"time"
+
+	"github.com/cristiandonosoc/gochart/pkg/backend"
+	"github.com/cristiandonosoc/gochart/pkg/ir"
+)
+
+var _ backend.GochartDocumentBackend = (*dotGochartBackend)(nil)
+
+type dotGochartBackend struct {
+	options *BackendOptions
+}
+
+type BackendOptions struct {
+	Time    time.Time
+	Version string
+}
+
+type Option func(*BackendOptions)
+
+func NewDotGochartBackend(opts ...Option) *dotGochartBackend {
+	options := &BackendOptions{
+		Version: "DEVELOPMENT",
+		Time:    time.Now(),
+	}
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	return &dotGochartBackend{
+		options: options,
+	}
+}
+
+func (dot *dotGochartBackend) Generate(sc *ir.Statechart) (io.Reader, error) {
+	initial := sc.InitialState()
+
+	var buf bytes.Buffer
+	gw := &graphWriter{w: &buf}
+
+	gw.linef("// File generated by Gochart version %q at %s", dot.options.Version, dot.options.Time)
+	gw.linef("// DO NOT MODIFY!")
+	gw.linef("")
+	gw.linef("digraph %s {", quote(sc.Name))
+	gw.indent++
+
+	// compound allows edges to start and end at the border of the clusters of composite states.
+	gw.linef("compound=true;")
+	gw.linef("node [shape=box, style=rounded];")
+	gw.linef("")
+
+	gw.initialMarker(initialID(nil), initial)
+	for _, root := range sc.Roots {
+		gw.state(root)
+	}
+
+	gw.linef("")
+	for _, transition := range sc.Transitions {
+		gw.transition(transition)
+	}
+
+	gw.indent--
+	gw.linef("}")
+
+	return &buf, nil
+}
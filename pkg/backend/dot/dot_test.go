@@ -0,0 +1,37 @@
+package dot
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/cristiandonosoc/gochart/pkg/backend/backendtest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func withTestInfo(o *BackendOptions) {
+	o.Version = backendtest.Version
+	o.Time = backendtest.Time
+}
+
+func TestGenerateGolden(t *testing.T) {
+	for _, name := range backendtest.ChartNames(t) {
+		t.Run(name, func(t *testing.T) {
+			got := backendtest.Generate(t, NewDotGochartBackend(withTestInfo), backendtest.ReadStatechart(t, name))
+			backendtest.CheckGolden(t, filepath.Join("testdata", name+".dot.golden"), got)
+		})
+	}
+}
+
+// TestGenerateClusters checks the layout rules of compound states, which graphviz can only draw as
+// clusters: the edges to and from them are clipped at the border of the cluster.
+func TestGenerateClusters(t *testing.T) {
+	history := backendtest.Generate(t, NewDotGochartBackend(), backendtest.ReadStatechart(t, "history"))
+	assert.Contains(t, history, "\t\"Playing\" -> \"Paused\" [label=\"Pause\", ltail=\"cluster_Playing\"];\n")
+	assert.Contains(t, history, "\t\"Explore\" -> \"Combat\" [label=\"Next\", lhead=\"cluster_Combat\"];\n")
+
+	// Regions are all entered at once, so the parallel state has no initial marker.
+	parallel := backendtest.Generate(t, NewDotGochartBackend(), backendtest.ReadStatechart(t, "parallel"))
+	assert.Contains(t, parallel, "\t\tsubgraph \"cluster_Movement\" {\n\t\t\tlabel=\"Movement\";\n\t\t\tstyle=dashed;\n")
+	assert.NotContains(t, parallel, `"Alive/initial"`)
+}
@@ -0,0 +1,146 @@
+package dot
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/cristiandonosoc/gochart/pkg/backend"
+	"github.com/cristiandonosoc/gochart/pkg/ir"
+)
+
+// The graph is laid out as follows:
+//
+//   - Atomic states are rounded boxes. Final states get a double border.
+//   - Composite states are clusters. As Graphviz cannot point edges to clusters, each one holds an
+//     invisible anchor node named after the state, and edges to it get clipped at the cluster border.
+//   - Regions of parallel states are dashed clusters within the parallel one.
+//   - History states are small circles labelled "H" (shallow) or "H*" (deep).
+//   - The initial state of the statechart and of every non-parallel composite state is pointed by an
+//     edge coming from a filled dot.
+//   - Edges are labelled "Trigger(args) [Guard] / Action1, Action2". Null transitions are dashed.
+
+// graphWriter writes the DOT statements with the indentation of the current nesting level.
+type graphWriter struct {
+	w      io.Writer
+	indent int
+}
+
+func (gw *graphWriter) linef(format string, args ...any) {
+	line := fmt.Sprintf(format, args...)
+	if line == "" {
+		fmt.Fprintln(gw.w)
+		return
+	}
+	fmt.Fprintf(gw.w, "%s%s\n", strings.Repeat("\t", gw.indent), line)
+}
+
+func (gw *graphWriter) state(state *ir.State) {
+	if state.IsHistory() {
+		label := "H"
+		if state.History == ir.History_Deep {
+			label = "H*"
+		}
+		gw.linef("%s [shape=circle, width=0.3, fixedsize=true, label=%s];", quote(state.Name), quote(label))
+		return
+	}
+
+	if state.IsAtomic() {
+		attributes := []string{"label=" + quote(state.Name)}
+		if state.Final {
+			attributes = append(attributes, "peripheries=2")
+		}
+		gw.linef("%s [%s];", quote(state.Name), strings.Join(attributes, ", "))
+		return
+	}
+
+	gw.linef("subgraph %s {", clusterID(state))
+	gw.indent++
+
+	label := state.Name
+	style := "rounded"
+	if state.Parallel {
+		label += " (parallel)"
+	}
+	if state.IsRegion() {
+		style = "dashed"
+	}
+	gw.linef("label=%s;", quote(label))
+	gw.linef("style=%s;", style)
+	gw.linef("%s [shape=point, style=invis, width=0, height=0, label=\"\"];", quote(state.Name))
+
+	// Regions of a parallel state are all entered at once, so there is no initial one to mark.
+	if !state.Parallel {
+		if initial := state.InitialChild(); initial != nil {
+			gw.initialMarker(initialID(state), initial)
+		}
+	}
+
+	for _, child := range state.Children {
+		gw.state(child)
+	}
+
+	gw.indent--
+	gw.linef("}")
+}
+
+// initialMarker writes the dot pointing to the initial state of a level.
+func (gw *graphWriter) initialMarker(id string, initial *ir.State) {
+	gw.linef("%s [shape=point, width=0.15, label=\"\"];", id)
+
+	var attributes []string
+	if !initial.IsAtomic() {
+		attributes = append(attributes, "lhead="+clusterID(initial))
+	}
+	gw.linef("%s -> %s%s;", id, quote(initial.Name), formatAttributes(attributes))
+}
+
+func (gw *graphWriter) transition(transition *ir.Transition) {
+	from, to := transition.From, transition.To
+
+	var attributes []string
+	if label := backend.TransitionLabel(transition); label != "" {
+		attributes = append(attributes, "label="+quote(label))
+	}
+	if transition.IsNullTransition() {
+		attributes = append(attributes, "style=dashed")
+	}
+
+	// Clipping an edge at a cluster that contains the other end makes no sense to Graphviz.
+	if !from.IsAtomic() && from != to && !to.IsDescendantOf(from) {
+		attributes = append(attributes, "ltail="+clusterID(from))
+	}
+	if !to.IsAtomic() && from != to && !from.IsDescendantOf(to) {
+		attributes = append(attributes, "lhead="+clusterID(to))
+	}
+
+	gw.linef("%s -> %s%s;", quote(from.Name), quote(to.Name), formatAttributes(attributes))
+}
+
+func formatAttributes(attributes []string) string {
+	if len(attributes) == 0 {
+		return ""
+	}
+	return " [" + strings.Join(attributes, ", ") + "]"
+}
+
+// clusterID returns the subgraph name of a composite state. Graphviz only draws subgraphs as boxes
+// when their name starts with "cluster".
+func clusterID(state *ir.State) string {
+	return quote("cluster_" + state.Name)
+}
+
+// initialID returns the node of the initial marker within |state|. nil means the top level.
+func initialID(state *ir.State) string {
+	if state == nil {
+		return quote("/initial")
+	}
+	return quote(state.Name + "/initial")
+}
+
+// quote returns |s| as a DOT quoted string.
+func quote(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	return `"` + s + `"`
+}
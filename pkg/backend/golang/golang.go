@@ -0,0 +1,174 @@
+// golang is a Gochart backend meant to generate a Go implementation of a statechart, so that Go
+// tools and servers can run the same state logic as the C++ code.
+//
+// For a statechart named Game, the generated file has:
+//
+//   - GameState, an enum of the states with a String method.
+//   - A GameTrigger<Trigger> struct per trigger, with a field per argument. The arguments are
+//     declared with C++ types, which get mapped to Go (see goTypes).
+//   - GameOwner, the interface with the enter/exit reactions, guards and actions that the owner of
+//     the statechart implements. The methods are named as the ones the C++ backend calls.
+//   - GameStatechart, which runs the statechart. Triggers are processed run-to-completion, as with
+//     the C++ backend: the ones raised while another one is being processed are queued.
+//
+// Go has no overloading, so a guard or action used with triggers that take different arguments gets
+// a method per trigger, named after it (eg. ActionSpawnDust_Land).
+package golang
+
+import (
+	"bytes"
+	_ "embed"
+	"fmt"
+	"go/format"
+	"io"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/Masterminds/sprig/v3"
+
+	"github.com/cristiandonosoc/gochart/pkg/backend"
+	"github.com/cristiandonosoc/gochart/pkg/ir"
+)
+
+var _ backend.GochartDocumentBackend = (*goGochartBackend)(nil)
+
+//go:embed statechart.go.tmpl
+var statechartTemplate string
+
+type goGochartBackend struct {
+	options *BackendOptions
+}
+
+type BackendOptions struct {
+	Time    time.Time
+	Version string
+
+	// Package is the package of the generated code. Defaults to the statechart name in lower case.
+	Package string
+
+	// Types maps C++ types to Go ones, on top of (and over) the built-in mappings. The Go types have
+	// to be available in the generated package.
+	Types map[string]string
+}
+
+type Option func(*BackendOptions)
+
+func NewGoGochartBackend(opts ...Option) *goGochartBackend {
+	options := &BackendOptions{
+		Version: "DEVELOPMENT",
+		Time:    time.Now(),
+	}
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	return &goGochartBackend{
+		options: options,
+	}
+}
+
+func (golang *goGochartBackend) Generate(sc *ir.Statechart) (io.Reader, error) {
+	context, err := newTemplateContext(sc, golang.options)
+	if err != nil {
+		return nil, fmt.Errorf("building template context: %w", err)
+	}
+
+	tmpl, err := template.New("statechart.go").Funcs(sprig.FuncMap()).Parse(statechartTemplate)
+	if err != nil {
+		return nil, fmt.Errorf("parsing template: %w", err)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, context); err != nil {
+		return nil, fmt.Errorf("executing template: %w", err)
+	}
+
+	// The template does not care about alignment, which gofmt does.
+	source, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("formatting generated code: %w", err)
+	}
+
+	return bytes.NewReader(source), nil
+}
+
+// templateContext has the information needed by the template.
+type templateContext struct {
+	BackendOptions
+	Statechart *ir.Statechart
+
+	// Names of the generated types.
+	StateType      string
+	StatechartType string
+	OwnerType      string
+
+	// Prefix is prepended to the unexported package level names, so that several statecharts can be
+	// generated within the same package.
+	Prefix string
+
+	Triggers    []*goTrigger
+	Transitions *backend.TransitionModel
+
+	triggerMap map[*ir.Trigger]*goTrigger
+}
+
+func newTemplateContext(sc *ir.Statechart, options *BackendOptions) (*templateContext, error) {
+	tc := &templateContext{
+		BackendOptions: *options,
+		Statechart:     sc,
+
+		StateType:      fmt.Sprintf("%sState", sc.Name),
+		StatechartType: fmt.Sprintf("%sStatechart", sc.Name),
+		OwnerType:      fmt.Sprintf("%sOwner", sc.Name),
+		Prefix:         unexportedName(sc.Name),
+	}
+
+	if tc.Package == "" {
+		tc.Package = strings.ToLower(sc.Name)
+	}
+
+	triggers, err := newGoTriggers(sc, &typeMapper{extra: options.Types})
+	if err != nil {
+		return nil, err
+	}
+	tc.Triggers = triggers
+
+	tc.triggerMap = make(map[*ir.Trigger]*goTrigger)
+	for _, trigger := range triggers {
+		tc.triggerMap[trigger.Trigger] = trigger
+	}
+
+	tc.Transitions = backend.NewTransitionModel(sc, backend.CallbackNaming_PerTrigger)
+
+	return tc, nil
+}
+
+// StateConst returns the constant of |state| in the state enum. nil is the top level of the
+// statechart, which is the None constant.
+func (tc *templateContext) StateConst(state *ir.State) string {
+	if state == nil {
+		return tc.StateType + "_None"
+	}
+	return fmt.Sprintf("%s_%s", tc.StateType, state.Name)
+}
+
+// TriggerStruct returns the name of the struct that holds the arguments of |trigger|.
+func (tc *templateContext) TriggerStruct(trigger *ir.Trigger) string {
+	return tc.triggerMap[trigger].StructName
+}
+
+// Call returns the call to |callback| over the owner, forwarding the trigger arguments.
+func (tc *templateContext) Call(callback *backend.Callback) string {
+	return callbackCall(callback, tc.triggerMap[callback.Trigger])
+}
+
+// Method returns the method of the owner interface for |callback|.
+func (tc *templateContext) Method(callback *backend.Callback) string {
+	return callbackMethod(callback, tc.triggerMap[callback.Trigger])
+}
+
+// Unexported returns |name| as an unexported Go name, for the methods named by the transition model.
+func (tc *templateContext) Unexported(name string) string {
+	return unexportedName(name)
+}
@@ -0,0 +1,267 @@
+package golang
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cristiandonosoc/gochart/pkg/backend"
+	"github.com/cristiandonosoc/gochart/pkg/frontend/gochart_lang"
+	"github.com/cristiandonosoc/gochart/pkg/frontend/yaml"
+	"github.com/cristiandonosoc/gochart/pkg/ir"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// The tests run the backend over the statecharts in pkg/ir/testdata. The generated code is compared
+// against the golden files in testdata, which are rewritten with:
+//
+//	go test ./pkg/backend/golang -update
+var update = flag.Bool("update", false, "update the golden files")
+
+const chartsDir = "../../ir/testdata"
+
+// chartNames returns the names of the charts to generate.
+func chartNames(t *testing.T) []string {
+	t.Helper()
+
+	paths, err := filepath.Glob(filepath.Join(chartsDir, "*.yaml"))
+	require.NoError(t, err)
+	require.NotEmpty(t, paths)
+
+	var names []string
+	for _, path := range paths {
+		names = append(names, strings.TrimSuffix(filepath.Base(path), ".yaml"))
+	}
+	return names
+}
+
+func readStatechart(t *testing.T, name string) *ir.Statechart {
+	t.Helper()
+
+	scdata, err := yaml.NewYamlFrontend().ProcessFromFile(filepath.Join(chartsDir, name+".yaml"))
+	require.NoError(t, err)
+
+	sc, err := ir.ProcessStatechartData(scdata)
+	require.NoError(t, err)
+
+	return sc
+}
+
+func generate(t *testing.T, sc *ir.Statechart, opts ...Option) string {
+	t.Helper()
+
+	opts = append([]Option{func(o *BackendOptions) {
+		o.Version = "TEST"
+		o.Time = time.Date(2023, time.July, 4, 0, 0, 0, 0, time.UTC)
+	}}, opts...)
+	r, err := NewGoGochartBackend(opts...).Generate(sc)
+	require.NoError(t, err)
+
+	data, err := io.ReadAll(r)
+	require.NoError(t, err)
+
+	return string(data)
+}
+
+func TestGenerateGolden(t *testing.T) {
+	for _, name := range chartNames(t) {
+		t.Run(name, func(t *testing.T) {
+			got := generate(t, readStatechart(t, name))
+			path := filepath.Join("testdata", name+".go.golden")
+
+			if *update {
+				require.NoError(t, os.WriteFile(path, []byte(got), 0644))
+				return
+			}
+
+			want, err := os.ReadFile(path)
+			require.NoError(t, err, "run with -update to create the golden files")
+			assert.Equal(t, string(want), got, "run with -update if the change is intended")
+		})
+	}
+}
+
+// TestRun builds the generated code along with an owner that logs every callback, and runs it as
+// the C++ backend tests do. Both have to behave the same, so the output is compared against the
+// run golden files of the C++ backend.
+func TestRun(t *testing.T) {
+	goBinary, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("no go binary found")
+	}
+
+	for _, name := range chartNames(t) {
+		t.Run(name, func(t *testing.T) {
+			sc := readStatechart(t, name)
+			code := generate(t, sc, func(o *BackendOptions) { o.Package = "main" })
+
+			context, err := newTemplateContext(sc, &BackendOptions{})
+			require.NoError(t, err)
+
+			dir := t.TempDir()
+			files := map[string]string{
+				"go.mod":        "module driver\n\ngo 1.20\n",
+				"statechart.go": code,
+				"main.go":       driver(context),
+			}
+			for filename, content := range files {
+				require.NoError(t, os.WriteFile(filepath.Join(dir, filename), []byte(content), 0644))
+			}
+
+			cmd := exec.Command(goBinary, "run", ".")
+			cmd.Dir = dir
+			out, err := cmd.CombinedOutput()
+			require.NoError(t, err, "running:\n%s", out)
+
+			want, err := os.ReadFile(filepath.Join("..", "cpp", "testdata", name+".run.golden"))
+			require.NoError(t, err)
+			assert.Equal(t, string(want), string(out))
+		})
+	}
+}
+
+// driver returns a main.go that drives the generated statechart. The owner logs every callback and
+// lets every guard pass. The guards and actions named after their trigger are logged with the plain
+// name, as the C++ owner that wrote the goldens overloads them instead.
+func driver(tc *templateContext) string {
+	var sb strings.Builder
+
+	sb.WriteString("package main\n\nimport \"fmt\"\n\ntype owner struct{}\n")
+	for _, method := range tc.Transitions.OwnerMethods {
+		name := method.Name
+		if method.Trigger != nil && (method.Kind == backend.Callback_Guard || method.Kind == backend.Callback_Action) {
+			name = strings.TrimSuffix(name, "_"+method.Trigger.Name)
+		}
+
+		fmt.Fprintf(&sb, "\nfunc (owner) %s {\n\tfmt.Println(\"  %s\")\n", tc.Method(method), name)
+		if method.IsGuard() {
+			sb.WriteString("\treturn true\n")
+		}
+		sb.WriteString("}\n")
+	}
+
+	fmt.Fprintf(&sb, "\nfunc printActive(sc *%s) {\n", tc.StatechartType)
+	sb.WriteString("\tfmt.Print(\"active:\")\n")
+	fmt.Fprintf(&sb, "\tfor state := %s(0); state < %s; state++ {\n", tc.StateType, tc.StateConst(nil))
+	sb.WriteString("\t\tif sc.IsActive(state) {\n\t\t\tfmt.Print(\" \", state)\n\t\t}\n\t}\n\tfmt.Println()\n}\n")
+
+	sb.WriteString("\nfunc main() {\n")
+	fmt.Fprintf(&sb, "\tsc := New%s(owner{})\n\n", tc.StatechartType)
+	sb.WriteString("\tfmt.Println(\"Activate\")\n\tsc.Activate()\n\tprintActive(sc)\n")
+	sb.WriteString("\n\tfor round := 0; round < 2; round++ {")
+	for _, trigger := range tc.Triggers {
+		args := make([]string, len(trigger.Fields))
+		for i, field := range trigger.Fields {
+			args[i] = fmt.Sprintf("*new(%s)", field.Type)
+		}
+		fmt.Fprintf(&sb, "\n\t\tfmt.Println(\"Trigger%s\")\n", trigger.Trigger.Name)
+		fmt.Fprintf(&sb, "\t\tsc.Trigger%s(%s)\n\t\tprintActive(sc)\n", trigger.Trigger.Name, strings.Join(args, ", "))
+	}
+	sb.WriteString("\t}\n")
+	sb.WriteString("\n\tfmt.Println(\"Deactivate\")\n\tsc.Deactivate()\n\tprintActive(sc)\n}\n")
+
+	return sb.String()
+}
+
+func TestGoType(t *testing.T) {
+	testcases := []struct {
+		cppType string
+		want    string
+	}{
+		{"int", "int32"},
+		{"std::uint8_t", "uint8"},
+		{"unsigned long long", "uint64"},
+		{"bool", "bool"},
+		{"double", "float64"},
+		{"const std::string&", "string"},
+		{"const char*", "string"},
+		{"const Vector&", "Vector"},
+		{"Player*", "*Player"},
+		{"std::vector<int>", "[]int32"},
+		{"const std::map<std::string, std::vector<float>>&", "map[string][]float32"},
+	}
+
+	types := &typeMapper{extra: map[string]string{
+		"Vector": "Vector",
+		"Player": "Player",
+	}}
+	for _, testcase := range testcases {
+		got, err := types.goType(testcase.cppType)
+		require.NoError(t, err, testcase.cppType)
+		assert.Equal(t, testcase.want, got, testcase.cppType)
+	}
+
+	_, err := types.goType("FVector")
+	assert.ErrorContains(t, err, `no Go type for C++ type "FVector"`)
+}
+
+func TestGenerateErrors(t *testing.T) {
+	testcases := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{
+			name: "unknown type",
+			input: `
+statechart Door {
+	trigger Open("const FVector& where")
+	state Closed { initial transition Opened { trigger Open } }
+	state Opened {}
+}`,
+			want: `trigger "Open", argument "where": no Go type for C++ type "const FVector&"`,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			scdata, err := gochart_lang.NewGochartLangFrontend().Process(strings.NewReader(tc.input))
+			require.NoError(t, err)
+			sc, err := ir.ProcessStatechartData(scdata)
+			require.NoError(t, err)
+
+			_, err = NewGoGochartBackend().Generate(sc)
+			assert.ErrorContains(t, err, tc.want)
+		})
+	}
+}
+
+// TestOverloads checks that the guards and actions called with different arguments get a method per
+// trigger, as Go has no overloading.
+func TestOverloads(t *testing.T) {
+	input := `
+statechart Door {
+	trigger Open("int code")
+	trigger Kick
+	state Closed {
+		initial
+		transition Opened { trigger Open action Log }
+		transition Opened { trigger Kick action Log }
+	}
+	state Opened {}
+}`
+	scdata, err := gochart_lang.NewGochartLangFrontend().Process(strings.NewReader(input))
+	require.NoError(t, err)
+	sc, err := ir.ProcessStatechartData(scdata)
+	require.NoError(t, err)
+
+	reader, err := NewGoGochartBackend().Generate(sc)
+	require.NoError(t, err)
+	data, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	got := string(data)
+
+	assert.Contains(t, got, "ActionLog_Open(code int32)\n")
+	assert.Contains(t, got, "ActionLog_Kick()\n")
+	assert.Contains(t, got, "sc.owner.ActionLog_Open(trigger.Code)")
+	assert.Contains(t, got, "sc.owner.ActionLog_Kick()")
+	assert.NotContains(t, got, "ActionLog(")
+}
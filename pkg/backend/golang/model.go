@@ -0,0 +1,93 @@
+package golang
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/cristiandonosoc/gochart/pkg/backend"
+	"github.com/cristiandonosoc/gochart/pkg/ir"
+)
+
+// The generated Go code follows the transition model shared with the other backends (see
+// backend.TransitionModel), so it executes the statechart exactly as the C++ one does. This file
+// only has how the triggers and the owner callbacks look in Go.
+
+// goTrigger is a trigger along with the Go struct that holds its arguments.
+type goTrigger struct {
+	Trigger    *ir.Trigger
+	StructName string
+	Fields     []*goField
+}
+
+type goField struct {
+	// Name is the exported name of the field in the trigger struct.
+	Name string
+	// Param is the name of the argument when passed as a parameter.
+	Param string
+	Type  string
+}
+
+// Params returns the parameters of a function that takes the arguments of the trigger.
+func (gt *goTrigger) Params() string {
+	params := make([]string, 0, len(gt.Fields))
+	for _, field := range gt.Fields {
+		params = append(params, fmt.Sprintf("%s %s", field.Param, field.Type))
+	}
+	return strings.Join(params, ", ")
+}
+
+// CallArgs returns the arguments to forward from the trigger struct, named "trigger".
+func (gt *goTrigger) CallArgs() string {
+	args := make([]string, 0, len(gt.Fields))
+	for _, field := range gt.Fields {
+		args = append(args, "trigger."+field.Name)
+	}
+	return strings.Join(args, ", ")
+}
+
+func newGoTriggers(sc *ir.Statechart, types *typeMapper) ([]*goTrigger, error) {
+	var triggers []*goTrigger
+	for _, trigger := range sc.Triggers {
+		gt := &goTrigger{
+			Trigger:    trigger,
+			StructName: fmt.Sprintf("%sTrigger%s", sc.Name, trigger.Name),
+		}
+
+		for _, arg := range trigger.Args {
+			goType, err := types.goType(arg.Type)
+			if err != nil {
+				return nil, fmt.Errorf("trigger %q, argument %q: %w", trigger.Name, arg.Name, err)
+			}
+
+			gt.Fields = append(gt.Fields, &goField{
+				Name:  exportedName(arg.Name),
+				Param: paramName(arg.Name),
+				Type:  goType,
+			})
+		}
+
+		triggers = append(triggers, gt)
+	}
+	return triggers, nil
+}
+
+// callbackCall returns the call to |callback|, forwarding the arguments from the trigger struct.
+func callbackCall(callback *backend.Callback, trigger *goTrigger) string {
+	args := ""
+	if trigger != nil {
+		args = trigger.CallArgs()
+	}
+	return fmt.Sprintf("%s(%s)", callback.Name, args)
+}
+
+// callbackMethod returns the method of the owner interface for |callback|.
+func callbackMethod(callback *backend.Callback, trigger *goTrigger) string {
+	params, ret := "", ""
+	if trigger != nil {
+		params = trigger.Params()
+	}
+	if callback.IsGuard() {
+		ret = " bool"
+	}
+	return fmt.Sprintf("%s(%s)%s", callback.Name, params, ret)
+}
@@ -0,0 +1,128 @@
+package golang
+
+import (
+	"fmt"
+	"go/token"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/cristiandonosoc/gochart/pkg/backend"
+)
+
+// The trigger arguments are declared with C++ types, which we translate to Go ones. Values are
+// passed as values: a "const std::string&" is a string. Pointers stay pointers, and some of the
+// standard containers map to their Go counterparts (eg. "std::vector<int>" is a []int32).
+
+// goTypes maps the C++ types of the trigger arguments to Go ones. The fixed width integer types are
+// also accepted with the "std::" prefix.
+var goTypes = map[string]string{
+	"bool":               "bool",
+	"char":               "byte",
+	"signed char":        "int8",
+	"unsigned char":      "uint8",
+	"short":              "int16",
+	"unsigned short":     "uint16",
+	"int":                "int32",
+	"unsigned":           "uint32",
+	"unsigned int":       "uint32",
+	"long":               "int64",
+	"unsigned long":      "uint64",
+	"long long":          "int64",
+	"unsigned long long": "uint64",
+	"int8_t":             "int8",
+	"uint8_t":            "uint8",
+	"int16_t":            "int16",
+	"uint16_t":           "uint16",
+	"int32_t":            "int32",
+	"uint32_t":           "uint32",
+	"int64_t":            "int64",
+	"uint64_t":           "uint64",
+	"size_t":             "uint",
+	"float":              "float32",
+	"double":             "float64",
+	"std::string":        "string",
+	"std::string_view":   "string",
+	"const char*":        "string",
+}
+
+// typeMapper translates C++ types into Go ones, with the extra mappings of the options taking
+// precedence over goTypes.
+type typeMapper struct {
+	extra map[string]string
+}
+
+func (tm *typeMapper) goType(cppType string) (string, error) {
+	cppType = strings.TrimSpace(cppType)
+
+	// Strings are the only pointers that do not map to Go pointers.
+	if cppType == "const char*" || cppType == "const char *" {
+		return "string", nil
+	}
+
+	// References and the const of values do not matter once passed by value.
+	base := strings.TrimSpace(strings.TrimRight(cppType, "&"))
+	if strings.HasSuffix(base, "*") {
+		pointee, err := tm.goType(strings.TrimSuffix(base, "*"))
+		if err != nil {
+			return "", err
+		}
+		return "*" + pointee, nil
+	}
+	base = strings.TrimSpace(strings.TrimPrefix(base, "const "))
+
+	if goType, ok := tm.extra[base]; ok {
+		return goType, nil
+	}
+	if goType, ok := goTypes[strings.TrimPrefix(base, "std::")]; ok && strings.HasSuffix(base, "_t") {
+		return goType, nil
+	}
+	if goType, ok := goTypes[base]; ok {
+		return goType, nil
+	}
+
+	if name, args, ok := backend.SplitTemplate(base); ok {
+		switch {
+		case name == "std::vector" && len(args) == 1:
+			elem, err := tm.goType(args[0])
+			if err != nil {
+				return "", err
+			}
+			return "[]" + elem, nil
+		case (name == "std::map" || name == "std::unordered_map") && len(args) == 2:
+			key, err := tm.goType(args[0])
+			if err != nil {
+				return "", err
+			}
+			value, err := tm.goType(args[1])
+			if err != nil {
+				return "", err
+			}
+			return fmt.Sprintf("map[%s]%s", key, value), nil
+		}
+	}
+
+	return "", fmt.Errorf("no Go type for C++ type %q", cppType)
+}
+
+// exportedName returns |name| with its first letter upper cased, as the fields of the trigger
+// structs are exported.
+func exportedName(name string) string {
+	r, size := utf8.DecodeRuneInString(name)
+	return string(unicode.ToUpper(r)) + name[size:]
+}
+
+// unexportedName returns |name| with its first letter lower cased.
+func unexportedName(name string) string {
+	r, size := utf8.DecodeRuneInString(name)
+	return string(unicode.ToLower(r)) + name[size:]
+}
+
+// paramName returns the name of a parameter for an argument named |name|, which could be a Go
+// keyword.
+func paramName(name string) string {
+	if token.IsKeyword(name) {
+		return name + "_"
+	}
+	return name
+}
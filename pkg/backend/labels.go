@@ -0,0 +1,50 @@
+package backend
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/cristiandonosoc/gochart/pkg/ir"
+)
+
+// Helpers shared by the diagram backends, so that all of them describe the statechart the same way.
+
+// TransitionLabel returns the description of a transition in the "Trigger(args) [Guard] / Actions"
+// form, omitting the parts that the transition does not have. Null transitions without guard nor
+// actions get an empty label.
+func TransitionLabel(transition *ir.Transition) string {
+	var parts []string
+	if trigger := transition.Trigger; trigger != nil {
+		if len(trigger.Args) > 0 {
+			parts = append(parts, fmt.Sprintf("%s(%s)", trigger.Name, strings.Join(trigger.ArgsStringList(), ", ")))
+		} else {
+			parts = append(parts, trigger.Name)
+		}
+	}
+	if transition.HasGuard() {
+		parts = append(parts, fmt.Sprintf("[%s]", transition.Guard))
+	}
+	if len(transition.Actions) > 0 {
+		parts = append(parts, "/ "+strings.Join(transition.Actions, ", "))
+	}
+	return strings.Join(parts, " ")
+}
+
+// ReactionLines describes the enter and exit reactions of |state|, one per line: "enter" and "exit"
+// for the default ones, and "enter on X" and "exit on X" for the ones specific to trigger X.
+func ReactionLines(state *ir.State) []string {
+	var lines []string
+	if state.DefaultEnter {
+		lines = append(lines, "enter")
+	}
+	for _, reaction := range state.EnterReactions {
+		lines = append(lines, "enter on "+reaction.Trigger.Name)
+	}
+	if state.DefaultExit {
+		lines = append(lines, "exit")
+	}
+	for _, reaction := range state.ExitReactions {
+		lines = append(lines, "exit on "+reaction.Trigger.Name)
+	}
+	return lines
+}
@@ -0,0 +1,123 @@
+package mermaid
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/cristiandonosoc/gochart/pkg/backend"
+	"github.com/cristiandonosoc/gochart/pkg/ir"
+)
+
+// The diagram is laid out as follows:
+//
+//   - Composite states are written as blocks with their substates, and the regions of parallel
+//     states are separated by "--".
+//   - The initial state of the statechart and of every non-parallel composite state is pointed by
+//     "[*]", and final states point to it.
+//   - Mermaid has no history states, so they are plain states labelled "H" (shallow) or "H*" (deep).
+//   - Enter and exit reactions are notes on the right of their state.
+//   - Transitions are labelled "Trigger(args) [Guard] / Action1, Action2". Mermaid cannot draw
+//     transitions between substates of different blocks unless they are declared in a block that
+//     holds both, so each transition is written in the innermost such block.
+
+// diagramWriter writes the diagram statements with the indentation of the current nesting level.
+type diagramWriter struct {
+	w      io.Writer
+	indent int
+
+	// transitions holds the transitions to be written within each block. nil is the top level.
+	transitions map[*ir.State][]*ir.Transition
+}
+
+func newDiagramWriter(w io.Writer, sc *ir.Statechart) *diagramWriter {
+	transitions := make(map[*ir.State][]*ir.Transition)
+	for _, transition := range sc.Transitions {
+		container := transitionContainer(transition)
+		transitions[container] = append(transitions[container], transition)
+	}
+
+	return &diagramWriter{
+		w:           w,
+		transitions: transitions,
+	}
+}
+
+func (dw *diagramWriter) linef(format string, args ...any) {
+	line := fmt.Sprintf(format, args...)
+	if line == "" {
+		fmt.Fprintln(dw.w)
+		return
+	}
+	fmt.Fprintf(dw.w, "%s%s\n", strings.Repeat("    ", dw.indent), line)
+}
+
+// block writes the contents of |container|, or of the top level if nil.
+func (dw *diagramWriter) block(container *ir.State, children []*ir.State, initial *ir.State) {
+	if initial != nil {
+		dw.linef("[*] --> %s", initial.Name)
+	}
+
+	for i, child := range children {
+		if container != nil && container.Parallel && i > 0 {
+			dw.linef("--")
+		}
+		dw.state(child)
+	}
+
+	for _, transition := range dw.transitions[container] {
+		if label := backend.TransitionLabel(transition); label != "" {
+			dw.linef("%s --> %s : %s", transition.From.Name, transition.To.Name, label)
+		} else {
+			dw.linef("%s --> %s", transition.From.Name, transition.To.Name)
+		}
+	}
+}
+
+func (dw *diagramWriter) state(state *ir.State) {
+	switch {
+	case state.IsHistory():
+		label := "H"
+		if state.History == ir.History_Deep {
+			label = "H*"
+		}
+		dw.linef("state %q as %s", label, state.Name)
+		return
+	case state.IsAtomic():
+		dw.linef("%s", state.Name)
+	default:
+		dw.linef("state %s {", state.Name)
+		dw.indent++
+		dw.block(state, state.Children, state.InitialChild())
+		dw.indent--
+		dw.linef("}")
+	}
+
+	if state.Final {
+		dw.linef("%s --> [*]", state.Name)
+	}
+
+	if lines := backend.ReactionLines(state); len(lines) > 0 {
+		dw.linef("note right of %s", state.Name)
+		dw.indent++
+		for _, line := range lines {
+			dw.linef("%s", line)
+		}
+		dw.indent--
+		dw.linef("end note")
+	}
+}
+
+// transitionContainer returns the innermost state that holds both ends of |transition| as
+// substates. Parallel states are skipped, as their blocks are split among the regions. nil means
+// the top level.
+func transitionContainer(transition *ir.Transition) *ir.State {
+	container := transition.From.Parent
+	for container != nil && !transition.To.IsDescendantOf(container) {
+		container = container.Parent
+	}
+	for container != nil && container.Parallel {
+		container = container.Parent
+	}
+	return container
+}
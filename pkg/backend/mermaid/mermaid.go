@@ -0,0 +1,55 @@
+// mermaid is a Gochart backend meant to render a statechart as a Mermaid state diagram.
+package mermaid
+
+import (
+	"bytes"
+	"io"
+	"time"
+
+	"github.com/cristiandonosoc/gochart/pkg/backend"
+	"github.com/cristiandonosoc/gochart/pkg/ir"
+)
+
+var _ backend.GochartDocumentBackend = (*mermaidGochartBackend)(nil)
+
+type mermaidGochartBackend struct {
+	options *BackendOptions
+}
+
+type BackendOptions struct {
+	Time    time.Time
+	Version string
+}
+
+type Option func(*BackendOptions)
+
+func NewMermaidGochartBackend(opts ...Option) *mermaidGochartBackend {
+	options := &BackendOptions{
+		Version: "DEVELOPMENT",
+		Time:    time.Now(),
+	}
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	return &mermaidGochartBackend{
+		options: options,
+	}
+}
+
+func (mermaid *mermaidGochartBackend) Generate(sc *ir.Statechart) (io.Reader, error) {
+	initial := sc.InitialState()
+
+	var buf bytes.Buffer
+	dw := newDiagramWriter(&buf, sc)
+
+	// Mermaid only accepts comments after the diagram type.
+	dw.linef("stateDiagram-v2")
+	dw.indent++
+	dw.linef("%%%% File generated by Gochart version %q at %s", mermaid.options.Version, mermaid.options.Time)
+	dw.linef("%%%% DO NOT MODIFY!")
+	dw.linef("")
+	dw.block(nil, sc.Roots, initial)
+
+	return &buf, nil
+}
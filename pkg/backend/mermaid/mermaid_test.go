@@ -0,0 +1,53 @@
+package mermaid
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/cristiandonosoc/gochart/pkg/backend/backendtest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func withTestInfo(o *BackendOptions) {
+	o.Version = backendtest.Version
+	o.Time = backendtest.Time
+}
+
+func TestGenerateGolden(t *testing.T) {
+	for _, name := range backendtest.ChartNames(t) {
+		t.Run(name, func(t *testing.T) {
+			got := backendtest.Generate(t, NewMermaidGochartBackend(withTestInfo), backendtest.ReadStatechart(t, name))
+			backendtest.CheckGolden(t, filepath.Join("testdata", name+".mmd.golden"), got)
+		})
+	}
+}
+
+// TestGenerateTransitionBlocks checks that transitions are written in the innermost block that holds
+// both states, as mermaid would otherwise draw the states again outside of their parent.
+func TestGenerateTransitionBlocks(t *testing.T) {
+	history := backendtest.Generate(t, NewMermaidGochartBackend(), backendtest.ReadStatechart(t, "history"))
+	assert.Contains(t, history, "\n            Melee --> Ranged : Switch\n        }\n")
+	assert.Contains(t, history, "\n        Explore --> Combat : Next\n")
+	assert.Contains(t, history, "\n    Paused --> PlayingDeep : Resume\n")
+
+	// Transitions across regions cannot be written within the parallel state, as its block is split.
+	regions := backendtest.Generate(t, NewMermaidGochartBackend(), backendtest.ParseStatechart(t, `
+statechart Regions {
+	trigger Go
+
+	state P {
+		initial
+		parallel
+		state R1 {
+			state R1A { initial transition R1B { trigger Go } }
+			state R1B { final }
+		}
+		state R2 {
+			state R2A { initial transition R1B { trigger Go } }
+		}
+	}
+}`))
+	assert.Contains(t, regions, "\n        }\n        --\n        state R2 {\n")
+	assert.Contains(t, regions, "\n    }\n    R2A --> R1B : Go\n")
+}
@@ -0,0 +1,107 @@
+package plantuml
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/cristiandonosoc/gochart/pkg/backend"
+	"github.com/cristiandonosoc/gochart/pkg/ir"
+)
+
+// The diagram is laid out as follows:
+//
+//   - Composite states are written as blocks with their substates, and the regions of parallel
+//     states are separated by "--".
+//   - The initial state of the statechart and of every non-parallel composite state is pointed by
+//     "[*]", and final states point to it.
+//   - History states are not declared. Transitions to them point to the "[H]" (shallow) or "[H*]"
+//     (deep) pseudo-state of their parent instead.
+//   - Enter and exit reactions are notes on the right of their state.
+//   - Transitions are labelled "Trigger(args) [Guard] / Action1, Action2" and written at the top
+//     level, as PlantUML resolves states by name. Null transitions are dashed.
+
+// diagramWriter writes the diagram statements with the indentation of the current nesting level.
+type diagramWriter struct {
+	w      io.Writer
+	indent int
+}
+
+func (dw *diagramWriter) linef(format string, args ...any) {
+	line := fmt.Sprintf(format, args...)
+	if line == "" {
+		fmt.Fprintln(dw.w)
+		return
+	}
+	fmt.Fprintf(dw.w, "%s%s\n", strings.Repeat("  ", dw.indent), line)
+}
+
+// block writes the contents of |container|, or of the top level if nil.
+func (dw *diagramWriter) block(container *ir.State, children []*ir.State, initial *ir.State) {
+	if initial != nil {
+		dw.linef("[*] --> %s", initial.Name)
+	}
+
+	first := true
+	for _, child := range children {
+		if child.IsHistory() {
+			continue
+		}
+		if container != nil && container.Parallel && !first {
+			dw.linef("--")
+		}
+		first = false
+		dw.state(child)
+	}
+}
+
+func (dw *diagramWriter) state(state *ir.State) {
+	if state.IsAtomic() {
+		dw.linef("state %s", state.Name)
+	} else {
+		dw.linef("state %s {", state.Name)
+		dw.indent++
+		dw.block(state, state.Children, state.InitialChild())
+		dw.indent--
+		dw.linef("}")
+	}
+
+	if state.Final {
+		dw.linef("%s --> [*]", state.Name)
+	}
+
+	if lines := backend.ReactionLines(state); len(lines) > 0 {
+		dw.linef("note right of %s", state.Name)
+		dw.indent++
+		for _, line := range lines {
+			dw.linef("%s", line)
+		}
+		dw.indent--
+		dw.linef("end note")
+	}
+}
+
+func (dw *diagramWriter) transition(transition *ir.Transition) {
+	arrow := "-->"
+	if transition.IsNullTransition() {
+		arrow = "-[dashed]->"
+	}
+
+	line := fmt.Sprintf("%s %s %s", transition.From.Name, arrow, stateRef(transition.To))
+	if label := backend.TransitionLabel(transition); label != "" {
+		line += " : " + label
+	}
+	dw.linef("%s", line)
+}
+
+// stateRef returns how a transition refers to |state|.
+func stateRef(state *ir.State) string {
+	switch state.History {
+	case ir.History_Shallow:
+		return state.Parent.Name + "[H]"
+	case ir.History_Deep:
+		return state.Parent.Name + "[H*]"
+	default:
+		return state.Name
+	}
+}
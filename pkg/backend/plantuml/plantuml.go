@@ -0,0 +1,61 @@
+// plantuml is a Gochart backend meant to render a statechart as a PlantUML state diagram.
+package plantuml
+
+import (
+	"bytes"
+	"io"
+	"time"
+
+	"github.com/cristiandonosoc/gochart/pkg/backend"
+	"github.com/cristiandonosoc/gochart/pkg/ir"
+)
+
+var _ backend.GochartDocumentBackend = (*plantumlGochartBackend)(nil)
+
+type plantumlGochartBackend struct {
+	options *BackendOptions
+}
+
+type BackendOptions struct {
+	Time    time.Time
+	Version string
+}
+
+type Option func(*BackendOptions)
+
+func NewPlantUMLGochartBackend(opts ...Option) *plantumlGochartBackend {
+	options := &BackendOptions{
+		Version: "DEVELOPMENT",
+		Time:    time.Now(),
+	}
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	return &plantumlGochartBackend{
+		options: options,
+	}
+}
+
+func (plantuml *plantumlGochartBackend) Generate(sc *ir.Statechart) (io.Reader, error) {
+	initial := sc.InitialState()
+
+	var buf bytes.Buffer
+	dw := &diagramWriter{w: &buf}
+
+	dw.linef("@startuml %s", sc.Name)
+	dw.linef("' File generated by Gochart version %q at %s", plantuml.options.Version, plantuml.options.Time)
+	dw.linef("' DO NOT MODIFY!")
+	dw.linef("")
+	dw.linef("hide empty description")
+	dw.linef("")
+	dw.block(nil, sc.Roots, initial)
+
+	dw.linef("")
+	for _, transition := range sc.Transitions {
+		dw.transition(transition)
+	}
+	dw.linef("@enduml")
+
+	return &buf, nil
+}
@@ -0,0 +1,34 @@
+package plantuml
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/cristiandonosoc/gochart/pkg/backend/backendtest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func withTestInfo(o *BackendOptions) {
+	o.Version = backendtest.Version
+	o.Time = backendtest.Time
+}
+
+func TestGenerateGolden(t *testing.T) {
+	for _, name := range backendtest.ChartNames(t) {
+		t.Run(name, func(t *testing.T) {
+			got := backendtest.Generate(t, NewPlantUMLGochartBackend(withTestInfo), backendtest.ReadStatechart(t, name))
+			backendtest.CheckGolden(t, filepath.Join("testdata", name+".puml.golden"), got)
+		})
+	}
+}
+
+// TestGenerateHistory checks that history states are referred through their parent, as plantuml has
+// no way to declare them.
+func TestGenerateHistory(t *testing.T) {
+	got := backendtest.Generate(t, NewPlantUMLGochartBackend(), backendtest.ReadStatechart(t, "history"))
+	assert.Contains(t, got, "\nPaused --> Playing[H*] : Resume\n")
+	assert.Contains(t, got, "\nPaused --> Playing[H] : ResumeFresh\n")
+	assert.NotContains(t, got, "state PlayingDeep")
+	assert.NotContains(t, got, "state PlayingShallow")
+}
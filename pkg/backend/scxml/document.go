@@ -0,0 +1,138 @@
+package scxml
+
+import (
+	"encoding/xml"
+	"strings"
+
+	"github.com/cristiandonosoc/gochart/pkg/ir"
+)
+
+// The statechart is mapped as follows:
+//
+//   - Every state becomes a <state>, <parallel>, <final> or <history> element nested within its
+//     parent. Initial children are given by the initial attribute of their parent.
+//   - Transitions become <transition> elements within their source, with the trigger as event and
+//     the guard as cond. Internal ones are marked with type="internal".
+//   - The default enter/exit reactions become empty <onentry>/<onexit> elements, and the reactions to
+//     specific triggers become <raise> elements of a second <onentry>/<onexit>.
+//   - SCXML has no concept of callbacks, so transition actions are kept in the gochart:actions
+//     attribute, which SCXML tools ignore. Trigger arguments have no equivalent and are dropped.
+
+// Namespace is the XML namespace of SCXML documents.
+const Namespace = "http://www.w3.org/2005/07/scxml"
+
+// GochartNamespace is the XML namespace of the gochart specific attributes.
+const GochartNamespace = "https://github.com/cristiandonosoc/gochart"
+
+type document struct {
+	XMLName      xml.Name        `xml:"scxml"`
+	Xmlns        string          `xml:"xmlns,attr"`
+	XmlnsGochart string          `xml:"xmlns:gochart,attr"`
+	Version      string          `xml:"version,attr"`
+	Name         string          `xml:"name,attr"`
+	Initial      string          `xml:"initial,attr"`
+	States       []*stateElement `xml:"state"`
+}
+
+type stateElement struct {
+	// XMLName holds the kind of state: state, parallel, final or history.
+	XMLName xml.Name
+
+	ID          string               `xml:"id,attr"`
+	Initial     string               `xml:"initial,attr,omitempty"`
+	Type        string               `xml:"type,attr,omitempty"`
+	OnEntry     []*reactionElement   `xml:"onentry"`
+	OnExit      []*reactionElement   `xml:"onexit"`
+	Transitions []*transitionElement `xml:"transition"`
+	States      []*stateElement      `xml:"state"`
+}
+
+type reactionElement struct {
+	Raises []*raiseElement `xml:"raise"`
+}
+
+type raiseElement struct {
+	Event string `xml:"event,attr"`
+}
+
+type transitionElement struct {
+	Event   string `xml:"event,attr,omitempty"`
+	Target  string `xml:"target,attr"`
+	Cond    string `xml:"cond,attr,omitempty"`
+	Type    string `xml:"type,attr,omitempty"`
+	Actions string `xml:"gochart:actions,attr,omitempty"`
+}
+
+func newDocument(sc *ir.Statechart, initial *ir.State) *document {
+	doc := &document{
+		Xmlns:        Namespace,
+		XmlnsGochart: GochartNamespace,
+		Version:      "1.0",
+		Name:         sc.Name,
+		Initial:      initial.Name,
+	}
+	for _, root := range sc.Roots {
+		doc.States = append(doc.States, newStateElement(root))
+	}
+	return doc
+}
+
+func newStateElement(state *ir.State) *stateElement {
+	se := &stateElement{
+		XMLName: xml.Name{Local: "state"},
+		ID:      state.Name,
+	}
+
+	switch {
+	case state.IsHistory():
+		se.XMLName.Local = "history"
+		se.Type = state.History.String()
+	case state.Parallel:
+		se.XMLName.Local = "parallel"
+	case state.Final:
+		se.XMLName.Local = "final"
+	}
+
+	if initial := state.InitialChild(); initial != nil {
+		se.Initial = initial.Name
+	}
+
+	se.OnEntry = newReactionElements(state.DefaultEnter, state.EnterReactions)
+	se.OnExit = newReactionElements(state.DefaultExit, state.ExitReactions)
+
+	for _, transition := range state.Transitions {
+		te := &transitionElement{
+			Target:  transition.To.Name,
+			Cond:    transition.Guard,
+			Actions: strings.Join(transition.Actions, " "),
+		}
+		if transition.Trigger != nil {
+			te.Event = transition.Trigger.Name
+		}
+		if transition.Internal {
+			te.Type = "internal"
+		}
+		se.Transitions = append(se.Transitions, te)
+	}
+
+	for _, child := range state.Children {
+		se.States = append(se.States, newStateElement(child))
+	}
+
+	return se
+}
+
+func newReactionElements(defaultReaction bool, reactions []*ir.StateReaction) []*reactionElement {
+	var elements []*reactionElement
+	if defaultReaction {
+		elements = append(elements, &reactionElement{})
+	}
+	if len(reactions) > 0 {
+		re := &reactionElement{}
+		for _, reaction := range reactions {
+			re.Raises = append(re.Raises, &raiseElement{Event: reaction.Trigger.Name})
+		}
+		elements = append(elements, re)
+	}
+	return elements
+}
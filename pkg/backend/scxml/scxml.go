@@ -0,0 +1,58 @@
+// scxml is a Gochart backend meant to serialize a statechart as a W3C SCXML document.
+package scxml
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/cristiandonosoc/gochart/pkg/backend"
+	"github.com/cristiandonosoc/gochart/pkg/ir"
+)
+
+var _ backend.GochartDocumentBackend = (*scxmlGochartBackend)(nil)
+
+type scxmlGochartBackend struct {
+	options *BackendOptions
+}
+
+type BackendOptions struct {
+	Time    time.Time
+	Version string
+}
+
+type Option func(*BackendOptions)
+
+func NewScxmlGochartBackend(opts ...Option) *scxmlGochartBackend {
+	options := &BackendOptions{
+		Version: "DEVELOPMENT",
+		Time:    time.Now(),
+	}
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	return &scxmlGochartBackend{
+		options: options,
+	}
+}
+
+func (scxml *scxmlGochartBackend) Generate(sc *ir.Statechart) (io.Reader, error) {
+	initial := sc.InitialState()
+
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+	fmt.Fprintf(&buf, "<!-- File generated by Gochart version %q at %s -->\n", scxml.options.Version, scxml.options.Time)
+	buf.WriteString("<!-- DO NOT MODIFY! -->\n")
+
+	encoder := xml.NewEncoder(&buf)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(newDocument(sc, initial)); err != nil {
+		return nil, fmt.Errorf("encoding scxml: %w", err)
+	}
+	buf.WriteString("\n")
+
+	return &buf, nil
+}
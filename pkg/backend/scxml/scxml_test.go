@@ -0,0 +1,40 @@
+package scxml
+
+import (
+	"encoding/xml"
+	"path/filepath"
+	"testing"
+
+	"github.com/cristiandonosoc/gochart/pkg/backend/backendtest"
+	scxml_frontend "github.com/cristiandonosoc/gochart/pkg/frontend/scxml"
+
+	"github.com/stretchr/testify/require"
+)
+
+func withTestInfo(o *BackendOptions) {
+	o.Version = backendtest.Version
+	o.Time = backendtest.Time
+}
+
+func TestGenerateGolden(t *testing.T) {
+	for _, name := range backendtest.ChartNames(t) {
+		t.Run(name, func(t *testing.T) {
+			got := backendtest.Generate(t, NewScxmlGochartBackend(withTestInfo), backendtest.ReadStatechart(t, name))
+			backendtest.CheckGolden(t, filepath.Join("testdata", name+".scxml.golden"), got)
+
+			// The output is well formed.
+			var doc struct{}
+			require.NoError(t, xml.Unmarshal([]byte(got), &doc))
+		})
+	}
+}
+
+// TestRoundTrip checks that the SCXML frontend reads back the statechart that was generated.
+func TestRoundTrip(t *testing.T) {
+	for _, name := range backendtest.ChartNames(t) {
+		t.Run(name, func(t *testing.T) {
+			backendtest.CheckRoundTrip(t, NewScxmlGochartBackend(withTestInfo), scxml_frontend.NewScxmlFrontend(),
+				backendtest.ReadStatechart(t, name))
+		})
+	}
+}
@@ -0,0 +1,437 @@
+package backend
+
+import (
+	"fmt"
+
+	"github.com/cristiandonosoc/gochart/pkg/ir"
+)
+
+// The transition model is the view of the statechart that the code generating backends follow, so
+// that the generated code of every language executes the statechart the same way. It says what to
+// call and in what order, but not how: each backend renders the callbacks (see Callback) and the
+// types of the arguments in its own language.
+
+// CallbackKind is what a callback over the owner of the statechart does.
+type CallbackKind int
+
+const (
+	// Callback_Enter is the reaction of a state to being entered.
+	Callback_Enter CallbackKind = iota
+
+	// Callback_Exit is the reaction of a state to being exited.
+	Callback_Exit
+
+	// Callback_Guard evaluates the guard of a transition, and returns whether it passes.
+	Callback_Guard
+
+	// Callback_Action runs an action of a transition.
+	Callback_Action
+)
+
+func (ck CallbackKind) String() string {
+	switch ck {
+	case Callback_Enter:
+		return "enter"
+	case Callback_Exit:
+		return "exit"
+	case Callback_Guard:
+		return "guard"
+	case Callback_Action:
+		return "action"
+	}
+
+	return fmt.Sprintf("<invalid callback kind %d>", int(ck))
+}
+
+// Callback is a method that the generated code calls over the owner of the statechart.
+type Callback struct {
+	Kind CallbackKind
+
+	// Name is the name of the method (eg. "StateIdle_OnEnter_Move" or "GuardCanJump").
+	Name string
+
+	// Trigger is the trigger whose arguments the method takes. nil if it takes none.
+	Trigger *ir.Trigger
+}
+
+// IsGuard returns whether the callback is a guard, which is the only kind returning a value.
+func (cb *Callback) IsGuard() bool {
+	return cb.Kind == Callback_Guard
+}
+
+// Step is a single enter or exit of a state, along with the owner callback it should call.
+type Step struct {
+	State *ir.State
+
+	// Callback is nil if the state has no reaction.
+	Callback *Callback
+}
+
+// Transition has all the information needed to generate the code that executes a transition.
+type Transition struct {
+	Index      int
+	Transition *ir.Transition
+
+	// Exits follow the exits of the transition. Only the states that are active at the moment of the
+	// transition get exited.
+	Exits []*Step
+
+	// Actions are the calls to the transition actions over the owner, which run between exiting and
+	// entering.
+	Actions []*Callback
+
+	// Entries follow the entries of the transition.
+	Entries []*Step
+
+	// HistoryRecords are the history states whose parent could be exited by this transition. Their
+	// values have to be recorded before exiting anything.
+	HistoryRecords []*ir.State
+
+	// HistoryRestore is set when the transition targets a history state. The entries then stop at
+	// the parent of the history state, and the rest is restored from what was recorded.
+	HistoryRestore *HistoryRestore
+}
+
+// FunctionName is the name of the generated function that executes this transition.
+func (t *Transition) FunctionName() string {
+	return fmt.Sprintf("ExecuteTransition%d", t.Index)
+}
+
+// HistoryRestore describes how to restore the substates of the parent of a history state.
+type HistoryRestore struct {
+	History *ir.State
+
+	// Branches are the substates that could have been recorded. Only the recorded ones get entered.
+	// For shallow history, each branch enters a child through its initial substates. For deep
+	// history, each branch is a single descendant.
+	Branches []*HistoryBranch
+
+	// Default are the entries to perform if no history has been recorded yet.
+	Default []*Step
+}
+
+// IsDeep returns whether the restored history is a deep one.
+func (hr *HistoryRestore) IsDeep() bool {
+	return hr.History.History == ir.History_Deep
+}
+
+type HistoryBranch struct {
+	State   *ir.State
+	Entries []*Step
+}
+
+// Dispatch represents how to select the transitions to take when a trigger arrives.
+type Dispatch struct {
+	// Trigger is nil for null transitions.
+	Trigger *ir.Trigger
+
+	// Atomics are the atomic states, in document order, that have a candidate transition for this
+	// trigger in themselves or one of their ancestors.
+	Atomics []*AtomicDispatch
+}
+
+// FunctionName is the name of the generated function that dispatches the trigger.
+func (d *Dispatch) FunctionName() string {
+	if d.Trigger == nil {
+		return "DispatchNullTransitions"
+	}
+	return fmt.Sprintf("Dispatch%s", d.Trigger.Name)
+}
+
+// AtomicDispatch are the candidate transitions for an active atomic state, in priority order:
+// transitions of inner states win over their ancestors, and then declaration order. The first
+// candidate whose guard passes is taken, so any candidate after an unguarded one is dropped.
+type AtomicDispatch struct {
+	State      *ir.State
+	Candidates []*Candidate
+}
+
+// Unconditional returns the transition to take if the only candidate has no guard.
+func (ad *AtomicDispatch) Unconditional() *Transition {
+	if len(ad.Candidates) == 1 && ad.Candidates[0].Guard == nil {
+		return ad.Candidates[0].Transition
+	}
+	return nil
+}
+
+type Candidate struct {
+	Transition *Transition
+
+	// Guard is the callback that evaluates the guard. nil if there is no guard.
+	Guard *Callback
+}
+
+// TransitionModel is the processed view of the statechart transitions that the templates use.
+type TransitionModel struct {
+	Transitions []*Transition
+	Dispatches  []*Dispatch
+
+	// ActivationEntries are the states entered when activating the statechart.
+	ActivationEntries []*Step
+
+	// DeactivationExits are all the states of the statechart, in the order they would be exited.
+	DeactivationExits []*Step
+
+	// OwnerMethods are all the callbacks the owner has to provide, each declared once: the guards
+	// and actions, and then the reactions of the states.
+	OwnerMethods []*Callback
+
+	// HistoryStates are all the history pseudo-states, in document order.
+	HistoryStates []*ir.State
+}
+
+func NewTransitionModel(sc *ir.Statechart, naming CallbackNaming) *TransitionModel {
+	tm := &TransitionModel{}
+	namer := NewCallbackNamer(sc, naming)
+
+	ordered := sc.DocumentOrder()
+
+	// The IR keeps the transitions in document order, so the indices are stable.
+	transitionMap := make(map[*ir.Transition]*Transition)
+	for i, transition := range sc.Transitions {
+		t := newTransition(i, transition, namer)
+		tm.Transitions = append(tm.Transitions, t)
+		transitionMap[transition] = t
+	}
+
+	// Null transitions get evaluated first, as the generated code runs them after every step.
+	tm.Dispatches = append(tm.Dispatches, newDispatch(ordered, transitionMap, namer, nil))
+	for _, trigger := range sc.Triggers {
+		tm.Dispatches = append(tm.Dispatches, newDispatch(ordered, transitionMap, namer, trigger))
+	}
+
+	for _, state := range ordered {
+		if state.IsHistory() {
+			tm.HistoryStates = append(tm.HistoryStates, state)
+		}
+	}
+
+	for _, state := range sc.InitialEntries() {
+		tm.ActivationEntries = append(tm.ActivationEntries, newEnterStep(state, nil))
+	}
+	for _, state := range sc.ExitOrder() {
+		tm.DeactivationExits = append(tm.DeactivationExits, newExitStep(state, nil))
+	}
+
+	tm.OwnerMethods = ownerMethods(ordered, namer)
+
+	return tm
+}
+
+func newTransition(index int, transition *ir.Transition, namer *CallbackNamer) *Transition {
+	t := &Transition{
+		Index:          index,
+		Transition:     transition,
+		HistoryRecords: transition.RecordedHistories(),
+	}
+
+	for _, state := range transition.Exits {
+		t.Exits = append(t.Exits, newExitStep(state, transition.Trigger))
+	}
+
+	for _, action := range transition.Actions {
+		t.Actions = append(t.Actions, &Callback{
+			Kind:    Callback_Action,
+			Name:    namer.Action(transition, action),
+			Trigger: transition.Trigger,
+		})
+	}
+
+	for _, state := range transition.Entries {
+		t.Entries = append(t.Entries, newEnterStep(state, transition.Trigger))
+	}
+
+	if transition.TargetsHistory() {
+		t.HistoryRestore = newHistoryRestore(transition.To, transition.Trigger)
+	}
+
+	return t
+}
+
+func newHistoryRestore(history *ir.State, trigger *ir.Trigger) *HistoryRestore {
+	parent := history.Parent
+	restore := &HistoryRestore{
+		History: history,
+	}
+
+	switch history.History {
+	case ir.History_Shallow:
+		for _, child := range parent.Children {
+			if child.IsHistory() {
+				continue
+			}
+
+			branch := &HistoryBranch{
+				State:   child,
+				Entries: []*Step{newEnterStep(child, trigger)},
+			}
+			for _, state := range child.DefaultEntries() {
+				branch.Entries = append(branch.Entries, newEnterStep(state, trigger))
+			}
+			restore.Branches = append(restore.Branches, branch)
+		}
+	case ir.History_Deep:
+		// All the active descendants were recorded, so entering them in document order restores the
+		// whole configuration.
+		for _, state := range parent.Descendants() {
+			if state.IsHistory() {
+				continue
+			}
+
+			restore.Branches = append(restore.Branches, &HistoryBranch{
+				State:   state,
+				Entries: []*Step{newEnterStep(state, trigger)},
+			})
+		}
+	}
+
+	for _, state := range parent.DefaultEntries() {
+		restore.Default = append(restore.Default, newEnterStep(state, trigger))
+	}
+
+	return restore
+}
+
+func newDispatch(ordered []*ir.State, transitionMap map[*ir.Transition]*Transition,
+	namer *CallbackNamer, trigger *ir.Trigger) *Dispatch {
+	dispatch := &Dispatch{
+		Trigger: trigger,
+	}
+
+	for _, state := range ordered {
+		// History states never become active, so they never dispatch.
+		if !state.IsAtomic() || state.IsHistory() {
+			continue
+		}
+
+		// We go from the innermost to the outermost state looking for candidates.
+		var candidates []*Candidate
+	CANDIDATE_LOOP:
+		for current := state; current != nil; current = current.Parent {
+			for _, transition := range current.Transitions {
+				if transition.Trigger != trigger {
+					continue
+				}
+
+				candidate := &Candidate{
+					Transition: transitionMap[transition],
+				}
+				candidates = append(candidates, candidate)
+
+				// Nothing after an unguarded transition can be taken.
+				if !transition.HasGuard() {
+					break CANDIDATE_LOOP
+				}
+				candidate.Guard = &Callback{
+					Kind:    Callback_Guard,
+					Name:    namer.Guard(transition),
+					Trigger: trigger,
+				}
+			}
+		}
+
+		if len(candidates) == 0 {
+			continue
+		}
+
+		dispatch.Atomics = append(dispatch.Atomics, &AtomicDispatch{
+			State:      state,
+			Candidates: candidates,
+		})
+	}
+
+	return dispatch
+}
+
+// CALLBACKS ---------------------------------------------------------------------------------------
+
+func newEnterStep(state *ir.State, trigger *ir.Trigger) *Step {
+	return &Step{
+		State:    state,
+		Callback: reactionCallback(state, Callback_Enter, trigger),
+	}
+}
+
+func newExitStep(state *ir.State, trigger *ir.Trigger) *Step {
+	return &Step{
+		State:    state,
+		Callback: reactionCallback(state, Callback_Exit, trigger),
+	}
+}
+
+// reactionCallback returns the reaction of |state| to being entered or exited by |trigger| (nil when
+// activating, deactivating or taking null transitions). A reaction specific to the trigger wins over
+// the default one. Returns nil if the state does not react.
+func reactionCallback(state *ir.State, kind CallbackKind, trigger *ir.Trigger) *Callback {
+	reactions, defaultReaction, suffix := state.EnterReactions, state.DefaultEnter, "OnEnter"
+	if kind == Callback_Exit {
+		reactions, defaultReaction, suffix = state.ExitReactions, state.DefaultExit, "OnExit"
+	}
+
+	if trigger != nil {
+		for _, reaction := range reactions {
+			if reaction.Trigger == trigger {
+				return &Callback{
+					Kind:    kind,
+					Name:    fmt.Sprintf("State%s_%s_%s", state.Name, suffix, trigger.Name),
+					Trigger: trigger,
+				}
+			}
+		}
+	}
+
+	if defaultReaction {
+		return &Callback{
+			Kind: kind,
+			Name: fmt.Sprintf("State%s_%s", state.Name, suffix),
+		}
+	}
+	return nil
+}
+
+// ownerMethods returns all the callbacks that the generated code calls over the owner.
+func ownerMethods(ordered []*ir.State, namer *CallbackNamer) []*Callback {
+	var methods []*Callback
+
+	// Guards and actions can be shared between transitions, so we only declare each overload once.
+	// Overloads are told apart by the argument types, as the names of the arguments can differ
+	// between triggers.
+	seen := make(map[string]struct{})
+	declare := func(callback *Callback) {
+		key := fmt.Sprintf("%s(%s)", callback.Name, Signature(callback.Trigger))
+		if _, ok := seen[key]; ok {
+			return
+		}
+		seen[key] = struct{}{}
+		methods = append(methods, callback)
+	}
+
+	for _, state := range ordered {
+		for _, transition := range state.Transitions {
+			if transition.HasGuard() {
+				declare(&Callback{Kind: Callback_Guard, Name: namer.Guard(transition), Trigger: transition.Trigger})
+			}
+			for _, action := range transition.Actions {
+				declare(&Callback{Kind: Callback_Action, Name: namer.Action(transition, action), Trigger: transition.Trigger})
+			}
+		}
+	}
+
+	for _, state := range ordered {
+		if callback := reactionCallback(state, Callback_Enter, nil); callback != nil {
+			methods = append(methods, callback)
+		}
+		for _, reaction := range state.EnterReactions {
+			methods = append(methods, reactionCallback(state, Callback_Enter, reaction.Trigger))
+		}
+
+		if callback := reactionCallback(state, Callback_Exit, nil); callback != nil {
+			methods = append(methods, callback)
+		}
+		for _, reaction := range state.ExitReactions {
+			methods = append(methods, reactionCallback(state, Callback_Exit, reaction.Trigger))
+		}
+	}
+
+	return methods
+}
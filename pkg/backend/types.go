@@ -0,0 +1,33 @@
+package backend
+
+import "strings"
+
+// SplitTemplate splits a C++ template instantiation (eg. "std::map<int, std::vector<int>>") into its
+// name and arguments, for the backends that map the types of the trigger arguments to another
+// language. Returns false if |cppType| is not a template instantiation.
+func SplitTemplate(cppType string) (name string, args []string, ok bool) {
+	open := strings.Index(cppType, "<")
+	if open < 0 || !strings.HasSuffix(cppType, ">") {
+		return "", nil, false
+	}
+
+	name = strings.TrimSpace(cppType[:open])
+	depth := 0
+	current := strings.Builder{}
+	for _, r := range cppType[open+1 : len(cppType)-1] {
+		switch {
+		case r == '<':
+			depth++
+		case r == '>':
+			depth--
+		case r == ',' && depth == 0:
+			args = append(args, strings.TrimSpace(current.String()))
+			current.Reset()
+			continue
+		}
+		current.WriteRune(r)
+	}
+	args = append(args, strings.TrimSpace(current.String()))
+
+	return name, args, true
+}
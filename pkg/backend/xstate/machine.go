@@ -0,0 +1,188 @@
+package xstate
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/cristiandonosoc/gochart/pkg/ir"
+)
+
+// The statechart is mapped as follows:
+//
+//   - Every state becomes a state node with its name as key and as "id", so that transitions can
+//     target it from anywhere as "#<Name>".
+//   - Transitions become entries of "on" keyed by trigger, or of "always" for null transitions,
+//     with guard and actions by name. External transitions to the source itself or one of its
+//     descendants set "reenter", as XState does not exit the source for them by default.
+//   - XState has no enter/exit reactions, so they become entry/exit actions named after the gochart
+//     callbacks: "State<Name>_OnEnter" for the default ones and "State<Name>_OnEnter_<Trigger>" for
+//     the ones specific to a trigger (same for "OnExit"). Note that XState runs all of them on entry,
+//     so the specific ones have to check the event themselves.
+//   - XState does not declare events, so the triggers and their arguments are kept in
+//     "meta.gochart.triggers".
+
+// object is a JSON object that keeps the order of its members, as XState uses the order of the
+// states.
+type object []member
+
+type member struct {
+	key   string
+	value any
+}
+
+func (o object) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString("{")
+	for i, m := range o {
+		if i > 0 {
+			buf.WriteString(",")
+		}
+
+		key, err := marshal(m.key)
+		if err != nil {
+			return nil, err
+		}
+		value, err := marshal(m.value)
+		if err != nil {
+			return nil, fmt.Errorf("marshalling %q: %w", m.key, err)
+		}
+
+		buf.Write(key)
+		buf.WriteString(":")
+		buf.Write(value)
+	}
+	buf.WriteString("}")
+	return buf.Bytes(), nil
+}
+
+// marshal is json.Marshal without escaping HTML characters, which are common in C++ types.
+func marshal(v any) ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(v); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
+
+func newMachine(sc *ir.Statechart, initial *ir.State, options *BackendOptions) object {
+	var triggers []object
+	for _, trigger := range sc.Triggers {
+		declaration := object{{"name", trigger.Name}}
+		if len(trigger.Args) > 0 {
+			declaration = append(declaration, member{"arguments", strings.Join(trigger.ArgsStringList(), ", ")})
+		}
+		triggers = append(triggers, declaration)
+	}
+
+	return object{
+		{"id", sc.Name},
+		{"initial", initial.Name},
+		{"meta", object{
+			{"gochart", object{
+				{"version", options.Version},
+				{"time", options.Time},
+				{"triggers", triggers},
+			}},
+		}},
+		{"states", newStates(sc.Roots)},
+	}
+}
+
+func newStates(states []*ir.State) object {
+	var o object
+	for _, state := range states {
+		o = append(o, member{state.Name, newState(state)})
+	}
+	return o
+}
+
+func newState(state *ir.State) object {
+	o := object{{"id", state.Name}}
+
+	switch {
+	case state.IsHistory():
+		o = append(o, member{"type", "history"}, member{"history", state.History.String()})
+	case state.Parallel:
+		o = append(o, member{"type", "parallel"})
+	case state.Final:
+		o = append(o, member{"type", "final"})
+	}
+
+	if initial := state.InitialChild(); initial != nil {
+		o = append(o, member{"initial", initial.Name})
+	}
+
+	if entry := reactionActions(state, "OnEnter", state.DefaultEnter, state.EnterReactions); entry != nil {
+		o = append(o, member{"entry", entry})
+	}
+	if exit := reactionActions(state, "OnExit", state.DefaultExit, state.ExitReactions); exit != nil {
+		o = append(o, member{"exit", exit})
+	}
+
+	var on object
+	var always []object
+	for _, transition := range state.Transitions {
+		if transition.IsNullTransition() {
+			always = append(always, newTransition(transition))
+			continue
+		}
+
+		// Transitions on the same trigger are grouped in declaration order, which is their priority.
+		found := false
+		for i, m := range on {
+			if m.key == transition.Trigger.Name {
+				on[i].value = append(m.value.([]object), newTransition(transition))
+				found = true
+				break
+			}
+		}
+		if !found {
+			on = append(on, member{transition.Trigger.Name, []object{newTransition(transition)}})
+		}
+	}
+	if on != nil {
+		o = append(o, member{"on", on})
+	}
+	if always != nil {
+		o = append(o, member{"always", always})
+	}
+
+	if len(state.Children) > 0 {
+		o = append(o, member{"states", newStates(state.Children)})
+	}
+
+	return o
+}
+
+func reactionActions(state *ir.State, kind string, defaultReaction bool, reactions []*ir.StateReaction) []string {
+	var actions []string
+	if defaultReaction {
+		actions = append(actions, fmt.Sprintf("State%s_%s", state.Name, kind))
+	}
+	for _, reaction := range reactions {
+		actions = append(actions, fmt.Sprintf("State%s_%s_%s", state.Name, kind, reaction.Trigger.Name))
+	}
+	return actions
+}
+
+func newTransition(transition *ir.Transition) object {
+	o := object{{"target", "#" + transition.To.Name}}
+	if transition.HasGuard() {
+		o = append(o, member{"guard", transition.Guard})
+	}
+	if len(transition.Actions) > 0 {
+		o = append(o, member{"actions", transition.Actions})
+	}
+
+	// XState only exits the source for targets within it when asked to.
+	to, from := transition.To, transition.From
+	if !transition.Internal && (to == from || to.IsDescendantOf(from)) {
+		o = append(o, member{"reenter", true})
+	}
+
+	return o
+}
@@ -0,0 +1,55 @@
+// xstate is a Gochart backend meant to write a statechart as an XState (v5) machine configuration
+// in JSON, which can be fed to createMachine.
+package xstate
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/cristiandonosoc/gochart/pkg/backend"
+	"github.com/cristiandonosoc/gochart/pkg/ir"
+)
+
+var _ backend.GochartDocumentBackend = (*xstateGochartBackend)(nil)
+
+type xstateGochartBackend struct {
+	options *BackendOptions
+}
+
+type BackendOptions struct {
+	Time    time.Time
+	Version string
+}
+
+type Option func(*BackendOptions)
+
+func NewXStateGochartBackend(opts ...Option) *xstateGochartBackend {
+	options := &BackendOptions{
+		Version: "DEVELOPMENT",
+		Time:    time.Now(),
+	}
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	return &xstateGochartBackend{
+		options: options,
+	}
+}
+
+func (xstate *xstateGochartBackend) Generate(sc *ir.Statechart) (io.Reader, error) {
+	initial := sc.InitialState()
+
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(newMachine(sc, initial, xstate.options)); err != nil {
+		return nil, fmt.Errorf("encoding machine: %w", err)
+	}
+
+	return &buf, nil
+}
@@ -0,0 +1,50 @@
+package xstate
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/cristiandonosoc/gochart/pkg/backend/backendtest"
+	xstate_frontend "github.com/cristiandonosoc/gochart/pkg/frontend/xstate"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func withTestInfo(o *BackendOptions) {
+	o.Version = backendtest.Version
+	o.Time = backendtest.Time
+}
+
+func TestGenerateGolden(t *testing.T) {
+	for _, name := range backendtest.ChartNames(t) {
+		t.Run(name, func(t *testing.T) {
+			got := backendtest.Generate(t, NewXStateGochartBackend(withTestInfo), backendtest.ReadStatechart(t, name))
+			backendtest.CheckGolden(t, filepath.Join("testdata", name+".json.golden"), got)
+
+			// The output is valid JSON.
+			var machine map[string]any
+			require.NoError(t, json.Unmarshal([]byte(got), &machine))
+		})
+	}
+}
+
+// TestRoundTrip checks that the XState frontend reads back the statechart that was generated.
+func TestRoundTrip(t *testing.T) {
+	for _, name := range backendtest.ChartNames(t) {
+		t.Run(name, func(t *testing.T) {
+			backendtest.CheckRoundTrip(t, NewXStateGochartBackend(withTestInfo), xstate_frontend.NewXStateFrontend(),
+				backendtest.ReadStatechart(t, name))
+		})
+	}
+}
+
+// TestGenerateReenter checks that only the external transitions to the source itself or to its
+// descendants ask XState to exit the source, as XState takes them as internal otherwise.
+func TestGenerateReenter(t *testing.T) {
+	got := backendtest.Generate(t, NewXStateGochartBackend(), backendtest.ReadStatechart(t, "internal"))
+	assert.Equal(t, 1, strings.Count(got, `"reenter": true`))
+	assert.Contains(t, got, "\"Reset\": [\n          {\n            \"target\": \"#Closed\",\n            \"reenter\": true\n          }")
+}
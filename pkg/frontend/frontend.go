@@ -30,5 +30,11 @@ func ProcessFromFile(gf GochartFrontend, path string) (*StatechartData, error) {
 		return nil, fmt.Errorf("reading %q: %w", path, err)
 	}
 
-	return gf.Process(bytes.NewReader(data))
+	scdata, err := gf.Process(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+
+	scdata.SetFile(path)
+	return scdata, nil
 }
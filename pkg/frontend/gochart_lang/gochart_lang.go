@@ -1,8 +1,22 @@
 // Package gochart_lang is the frontend for what we can "gochart_lang", which is a custom spec
 // language to describe statecharts.
+//
+// Charts can be split across files with `include "path"`, where the path is relative to the
+// including file and the included file holds a statechart of its own, whose name is ignored. At the
+// top level of a statechart, the triggers, states and transitions of the included file are merged
+// before the ones of the chart. Within a state, the top level states of the included file are
+// nested within it. See frontend.Includer for how includes are resolved.
 package gochart_lang
 
-import ()
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/cristiandonosoc/gochart/pkg/frontend"
+)
+
+var _ frontend.GochartFrontend = (*GochartLangFrontend)(nil)
 
 type GochartLangFrontend struct {
 }
@@ -11,3 +25,149 @@ type GochartLangFrontend struct {
 func NewGochartLangFrontend() *GochartLangFrontend {
 	return &GochartLangFrontend{}
 }
+
+func (gf *GochartLangFrontend) Process(r io.Reader) (*frontend.StatechartData, error) {
+	scanner := NewScanner()
+	scdata, errs := scanner.Scan(r)
+	if errs != nil {
+		return nil, fmt.Errorf("scanning gochart_lang input: %w", errors.Join(errs...))
+	}
+
+	return scdata, nil
+}
+
+func (gf *GochartLangFrontend) ProcessFromFile(path string) (*frontend.StatechartData, error) {
+	includer := frontend.NewIncluder()
+	scdata, err := includer.Include(path, frontend.Position{}, scanIncluded(includer))
+	if err != nil {
+		return nil, fmt.Errorf("scanning gochart_lang input: %w", err)
+	}
+
+	return scdata, nil
+}
+
+// scanIncluded returns the function that scans a file read by |includer|.
+func scanIncluded(includer *frontend.Includer) func(r io.Reader) (*frontend.StatechartData, error) {
+	return func(r io.Reader) (*frontend.StatechartData, error) {
+		scdata, errs := NewScanner().scan(r, includer)
+		return scdata, errors.Join(errs...)
+	}
+}
+
+// LOWERING ----------------------------------------------------------------------------------------
+
+// lowerer flattens the AST of a statechart into the frontend representation, which is what the rest
+// of the program understands. Nested states are converted into parent references and transitions
+// get the state they're declared in as their source.
+type lowerer struct {
+	scdata   *frontend.StatechartData
+	includer *frontend.Includer
+	errs     []error
+}
+
+func (n *ASTNodeStatechart) toStatechartData(includer *frontend.Includer) (*frontend.StatechartData, []error) {
+	l := &lowerer{
+		scdata: &frontend.StatechartData{
+			Name: n.name.literal,
+			Pos:  n.name.pos(),
+		},
+		includer: includer,
+	}
+
+	l.include(n.includes, "")
+
+	for _, trigger := range n.triggers {
+		tdata := &frontend.TriggerData{
+			Name:  trigger.name.literal,
+			Index: len(l.scdata.Triggers),
+			Pos:   trigger.name.pos(),
+		}
+		if trigger.arguments != nil {
+			tdata.ArgumentsString = trigger.arguments.literal
+		}
+
+		l.scdata.Triggers = append(l.scdata.Triggers, tdata)
+	}
+
+	for _, state := range n.states {
+		l.lowerState(state, "")
+	}
+
+	if l.errs != nil {
+		return nil, l.errs
+	}
+
+	return l.scdata, nil
+}
+
+func (l *lowerer) lowerState(n *ASTNodeState, parent string) {
+	scdata := l.scdata
+	sdata := &frontend.StateData{
+		Name:         n.name.literal,
+		Initial:      n.initial,
+		Parallel:     n.parallel,
+		Parent:       parent,
+		DefaultEnter: n.defaultEnter,
+		DefaultExit:  n.defaultExit,
+		Final:        n.final,
+		Index:        len(scdata.States),
+		Pos:          n.name.pos(),
+	}
+	if n.history != nil {
+		sdata.History = n.history.literal
+	}
+	for _, reaction := range n.enterReactions {
+		sdata.EnterReactionTriggers = append(sdata.EnterReactionTriggers, reaction.literal)
+	}
+	for _, reaction := range n.exitReactions {
+		sdata.ExitReactionTriggers = append(sdata.ExitReactionTriggers, reaction.literal)
+	}
+	scdata.States = append(scdata.States, sdata)
+
+	for _, transition := range n.transitions {
+		tdata := &frontend.TransitionData{
+			From:     sdata.Name,
+			To:       transition.target.literal,
+			Internal: transition.internal,
+			Index:    len(scdata.Transitions),
+			Pos:      transition.target.pos(),
+		}
+		if transition.trigger != nil {
+			tdata.Trigger = transition.trigger.literal
+		}
+		if transition.guard != nil {
+			tdata.Guard = transition.guard.literal
+		}
+		for _, action := range transition.actions {
+			tdata.Actions = append(tdata.Actions, action.literal)
+		}
+
+		scdata.Transitions = append(scdata.Transitions, tdata)
+	}
+
+	for _, child := range n.children {
+		l.lowerState(child, sdata.Name)
+	}
+
+	l.include(n.includes, sdata.Name)
+}
+
+// include merges the included files, with their top level states nested within |parent|.
+func (l *lowerer) include(includes []*ASTNodeInclude, parent string) {
+	for _, include := range includes {
+		path := include.path
+		site := frontend.Position{Line: path.line, Column: path.char}
+		included, err := l.includer.Include(path.literal, site, scanIncluded(l.includer))
+		if err != nil {
+			l.errs = append(l.errs, fmt.Errorf("line %d, char %d: including %q: %w", path.line, path.char,
+				path.literal, err))
+			continue
+		}
+
+		// Already included elsewhere.
+		if included == nil {
+			continue
+		}
+		l.scdata.Merge(included, parent)
+	}
+}
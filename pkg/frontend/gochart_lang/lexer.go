@@ -1,6 +1,10 @@
 package gochart_lang
 
-import ()
+import (
+	"fmt"
+
+	"github.com/cristiandonosoc/gochart/pkg/frontend"
+)
 
 // TokenIdentifier represents a single token of our parser.
 type TokenIdentifier int64
@@ -26,6 +30,7 @@ const (
 	Token_KeywordState      // state
 	Token_KeywordTransition // transition
 	Token_KeywordTrigger    // trigger
+	Token_KeywordInclude    // include
 
 	Token_Identifier
 
@@ -51,3 +56,40 @@ func NewToken(id TokenIdentifier) Token {
 func (t *Token) valid() bool {
 	return t.id != Token_Invalid
 }
+
+// pos returns where the token is within the input.
+func (t *Token) pos() frontend.Position {
+	return frontend.Position{
+		Line:   t.line,
+		Column: t.char,
+	}
+}
+
+// describe returns a human readable representation of the token, meant for error messages.
+func (t *Token) describe() string {
+	switch t.id {
+	case Token_EOF:
+		return "end of input"
+	case Token_StringLiteral:
+		return fmt.Sprintf("string %q", t.literal)
+	case Token_Identifier:
+		return fmt.Sprintf("identifier %q", t.literal)
+	case Token_LeftParen:
+		return "'('"
+	case Token_RightParen:
+		return "')'"
+	case Token_LeftBrace:
+		return "'{'"
+	case Token_RightBrace:
+		return "'}'"
+	case Token_LeftBracket:
+		return "'['"
+	case Token_RightBracket:
+		return "']'"
+	}
+
+	if t.literal != "" {
+		return fmt.Sprintf("%q", t.literal)
+	}
+	return fmt.Sprintf("token %d", t.id)
+}
@@ -2,6 +2,7 @@ package gochart_lang
 
 import (
 	"fmt"
+	"strings"
 )
 
 // Parser is an object capable of taking tokens of the gochart_lang language and parse it against
@@ -13,17 +14,37 @@ type Parser struct {
 
 // Parse receives a slice of tokens as given by the |Scanner| and tries to match it against the
 // gochart_lang grammar.
-func (p *Parser) Parse(tokens []*Token) error {
+func (p *Parser) Parse(tokens []*Token) (*ASTNodeRoot, error) {
 	p.tokens = tokens
 	p.current = 0
 
-	return fmt.Errorf("NOT IMPLEMENTED")
+	// We need to have an EOF token to know when to stop.
+	if len(p.tokens) == 0 || p.tokens[len(p.tokens)-1].id != Token_EOF {
+		return nil, fmt.Errorf("token stream is not terminated by EOF. Likely a bug")
+	}
+
+	root, err := p.parseRoot()
+	if err != nil {
+		return nil, err
+	}
+
+	return root, nil
+}
+
+// ParseError is a custom error associated with parsing, which points to the offending token.
+type ParseError struct {
+	ErrorToken Token
+	Message    string
+}
+
+func (pe *ParseError) Error() string {
+	return fmt.Sprintf("line %d, char %d: %s", pe.ErrorToken.line, pe.ErrorToken.char, pe.Message)
 }
 
 // RULES -------------------------------------------------------------------------------------------
 
 type ASTNode interface {
-	Print()
+	Print(sb *strings.Builder, indent int)
 }
 
 var _ ASTNode = (*ASTNodeRoot)(nil)
@@ -32,7 +53,7 @@ type ASTNodeRoot struct {
 	statecharts []*ASTNodeStatechart
 }
 
-// root -> statechart+
+// root -> statechart+ EOF
 func (p *Parser) parseRoot() (*ASTNodeRoot, error) {
 	root := &ASTNodeRoot{}
 
@@ -51,17 +72,338 @@ func (p *Parser) parseRoot() (*ASTNodeRoot, error) {
 		root.statecharts = append(root.statecharts, sc)
 	}
 
+	if len(root.statecharts) == 0 {
+		return nil, p.errorf(p.peek(), "expected at least one statechart")
+	}
+
+	if !p.atEnd() {
+		return nil, p.errorf(p.peek(), "expected statechart, got %s", p.peek().describe())
+	}
+
 	return root, nil
 }
 
 var _ ASTNode = (*ASTNodeStatechart)(nil)
 
 type ASTNodeStatechart struct {
+	name     *Token
+	includes []*ASTNodeInclude
+	triggers []*ASTNodeTrigger
+	states   []*ASTNodeState
 }
 
-// statechart -> STATECHART LEFT_BRACE RIGHT_BRACE
+// statechart -> STATECHART IDENTIFIER LEFT_BRACE (include | trigger | state)* RIGHT_BRACE
 func (p *Parser) parseStatechart() (*ASTNodeStatechart, bool, error) {
-	return nil, false, fmt.Errorf("NOT IMPLEMENTED")
+	if !p.match(Token_KeywordStatechart) {
+		return nil, false, nil
+	}
+
+	name, err := p.consume(Token_Identifier, "expected statechart name")
+	if err != nil {
+		return nil, false, err
+	}
+
+	sc := &ASTNodeStatechart{
+		name: name,
+	}
+
+	if _, err := p.consume(Token_LeftBrace, "expected '{' after statechart name"); err != nil {
+		return nil, false, err
+	}
+
+	for !p.check(Token_RightBrace) && !p.atEnd() {
+		if include, ok, err := p.parseInclude(); err != nil {
+			return nil, false, fmt.Errorf("statechart %q: parsing include: %w", name.literal, err)
+		} else if ok {
+			sc.includes = append(sc.includes, include)
+			continue
+		}
+
+		if trigger, ok, err := p.parseTrigger(); err != nil {
+			return nil, false, fmt.Errorf("statechart %q: parsing trigger: %w", name.literal, err)
+		} else if ok {
+			sc.triggers = append(sc.triggers, trigger)
+			continue
+		}
+
+		if state, ok, err := p.parseState(); err != nil {
+			return nil, false, fmt.Errorf("statechart %q: parsing state: %w", name.literal, err)
+		} else if ok {
+			sc.states = append(sc.states, state)
+			continue
+		}
+
+		return nil, false, p.errorf(p.peek(), "expected include, trigger or state, got %s", p.peek().describe())
+	}
+
+	if _, err := p.consume(Token_RightBrace, "expected '}' to close statechart"); err != nil {
+		return nil, false, err
+	}
+
+	return sc, true, nil
+}
+
+var _ ASTNode = (*ASTNodeInclude)(nil)
+
+type ASTNodeInclude struct {
+	path *Token
+}
+
+// include -> INCLUDE STRING_LITERAL
+func (p *Parser) parseInclude() (*ASTNodeInclude, bool, error) {
+	if !p.match(Token_KeywordInclude) {
+		return nil, false, nil
+	}
+
+	path, err := p.consume(Token_StringLiteral, "expected path of the included file")
+	if err != nil {
+		return nil, false, err
+	}
+
+	return &ASTNodeInclude{
+		path: path,
+	}, true, nil
+}
+
+var _ ASTNode = (*ASTNodeTrigger)(nil)
+
+type ASTNodeTrigger struct {
+	name      *Token
+	arguments *Token
+}
+
+// trigger -> TRIGGER IDENTIFIER (LEFT_PAREN STRING_LITERAL? RIGHT_PAREN)?
+func (p *Parser) parseTrigger() (*ASTNodeTrigger, bool, error) {
+	if !p.match(Token_KeywordTrigger) {
+		return nil, false, nil
+	}
+
+	name, err := p.consume(Token_Identifier, "expected trigger name")
+	if err != nil {
+		return nil, false, err
+	}
+
+	trigger := &ASTNodeTrigger{
+		name: name,
+	}
+
+	// The arguments are optional.
+	if p.match(Token_LeftParen) {
+		if p.match(Token_StringLiteral) {
+			trigger.arguments = p.prev()
+		}
+
+		if _, err := p.consume(Token_RightParen, "expected ')' to close trigger arguments"); err != nil {
+			return nil, false, err
+		}
+	}
+
+	return trigger, true, nil
+}
+
+var _ ASTNode = (*ASTNodeState)(nil)
+
+type ASTNodeState struct {
+	name *Token
+
+	initial      bool
+	parallel     bool
+	history      *Token
+	defaultEnter bool
+	defaultExit  bool
+	final        bool
+
+	enterReactions []*Token
+	exitReactions  []*Token
+
+	children    []*ASTNodeState
+	transitions []*ASTNodeTransition
+	includes    []*ASTNodeInclude
+}
+
+// stateFlags are the identifiers that can appear within a state block and take no value.
+var stateFlags = map[string]func(*ASTNodeState){
+	"initial":       func(s *ASTNodeState) { s.initial = true },
+	"parallel":      func(s *ASTNodeState) { s.parallel = true },
+	"default_enter": func(s *ASTNodeState) { s.defaultEnter = true },
+	"default_exit":  func(s *ASTNodeState) { s.defaultExit = true },
+	"final":         func(s *ASTNodeState) { s.final = true },
+}
+
+// state      -> STATE IDENTIFIER LEFT_BRACE state_item* RIGHT_BRACE
+// state_item -> state | transition | include | flag | history | reaction
+// flag       -> "initial" | "parallel" | "default_enter" | "default_exit" | "final"
+// history    -> "history" IDENTIFIER
+// reaction   -> ("enter_reaction" | "exit_reaction") IDENTIFIER
+func (p *Parser) parseState() (*ASTNodeState, bool, error) {
+	if !p.match(Token_KeywordState) {
+		return nil, false, nil
+	}
+
+	name, err := p.consume(Token_Identifier, "expected state name")
+	if err != nil {
+		return nil, false, err
+	}
+
+	state := &ASTNodeState{
+		name: name,
+	}
+
+	if _, err := p.consume(Token_LeftBrace, "expected '{' after state name"); err != nil {
+		return nil, false, err
+	}
+
+	for !p.check(Token_RightBrace) && !p.atEnd() {
+		if child, ok, err := p.parseState(); err != nil {
+			return nil, false, fmt.Errorf("state %q: %w", name.literal, err)
+		} else if ok {
+			state.children = append(state.children, child)
+			continue
+		}
+
+		if transition, ok, err := p.parseTransition(); err != nil {
+			return nil, false, fmt.Errorf("state %q: parsing transition: %w", name.literal, err)
+		} else if ok {
+			state.transitions = append(state.transitions, transition)
+			continue
+		}
+
+		if include, ok, err := p.parseInclude(); err != nil {
+			return nil, false, fmt.Errorf("state %q: parsing include: %w", name.literal, err)
+		} else if ok {
+			state.includes = append(state.includes, include)
+			continue
+		}
+
+		// Otherwise, it has to be an attribute of the state.
+		attr, err := p.consume(Token_Identifier, "expected state, transition or attribute")
+		if err != nil {
+			return nil, false, fmt.Errorf("state %q: %w", name.literal, err)
+		}
+
+		if setFlag, ok := stateFlags[attr.literal]; ok {
+			setFlag(state)
+			continue
+		}
+
+		switch attr.literal {
+		case "history":
+			if state.history != nil {
+				return nil, false, p.errorf(attr, "state %q: history defined twice", name.literal)
+			}
+
+			kind, err := p.consume(Token_Identifier, "expected history kind")
+			if err != nil {
+				return nil, false, fmt.Errorf("state %q: %w", name.literal, err)
+			}
+			state.history = kind
+		case "enter_reaction":
+			trigger, err := p.consume(Token_Identifier, "expected trigger name for enter reaction")
+			if err != nil {
+				return nil, false, fmt.Errorf("state %q: %w", name.literal, err)
+			}
+			state.enterReactions = append(state.enterReactions, trigger)
+		case "exit_reaction":
+			trigger, err := p.consume(Token_Identifier, "expected trigger name for exit reaction")
+			if err != nil {
+				return nil, false, fmt.Errorf("state %q: %w", name.literal, err)
+			}
+			state.exitReactions = append(state.exitReactions, trigger)
+		default:
+			return nil, false, p.errorf(attr, "state %q: unknown state attribute %q", name.literal, attr.literal)
+		}
+	}
+
+	if _, err := p.consume(Token_RightBrace, "expected '}' to close state"); err != nil {
+		return nil, false, fmt.Errorf("state %q: %w", name.literal, err)
+	}
+
+	return state, true, nil
+}
+
+var _ ASTNode = (*ASTNodeTransition)(nil)
+
+type ASTNodeTransition struct {
+	// target is the state this transition goes to. The source is the state that holds it.
+	target   *Token
+	trigger  *Token
+	guard    *Token
+	actions  []*Token
+	internal bool
+}
+
+// transition      -> TRANSITION IDENTIFIER LEFT_BRACE transition_item* RIGHT_BRACE
+// transition_item -> TRIGGER IDENTIFIER | "guard" IDENTIFIER | "action" IDENTIFIER | "internal"
+func (p *Parser) parseTransition() (*ASTNodeTransition, bool, error) {
+	if !p.match(Token_KeywordTransition) {
+		return nil, false, nil
+	}
+
+	target, err := p.consume(Token_Identifier, "expected transition target state")
+	if err != nil {
+		return nil, false, err
+	}
+
+	transition := &ASTNodeTransition{
+		target: target,
+	}
+
+	if _, err := p.consume(Token_LeftBrace, "expected '{' after transition target"); err != nil {
+		return nil, false, err
+	}
+
+	for !p.check(Token_RightBrace) && !p.atEnd() {
+		if p.match(Token_KeywordTrigger) {
+			if transition.trigger != nil {
+				return nil, false, p.errorf(p.prev(), "transition to %q already has a trigger", target.literal)
+			}
+
+			trigger, err := p.consume(Token_Identifier, "expected trigger name")
+			if err != nil {
+				return nil, false, err
+			}
+			transition.trigger = trigger
+			continue
+		}
+
+		if p.check(Token_Identifier) && p.peek().literal == "guard" {
+			p.advance()
+			if transition.guard != nil {
+				return nil, false, p.errorf(p.prev(), "transition to %q already has a guard", target.literal)
+			}
+
+			guard, err := p.consume(Token_Identifier, "expected guard name")
+			if err != nil {
+				return nil, false, err
+			}
+			transition.guard = guard
+			continue
+		}
+
+		if p.check(Token_Identifier) && p.peek().literal == "action" {
+			p.advance()
+			action, err := p.consume(Token_Identifier, "expected action name")
+			if err != nil {
+				return nil, false, err
+			}
+			transition.actions = append(transition.actions, action)
+			continue
+		}
+
+		if p.check(Token_Identifier) && p.peek().literal == "internal" {
+			p.advance()
+			transition.internal = true
+			continue
+		}
+
+		return nil, false, p.errorf(p.peek(), "expected transition attribute, got %s", p.peek().describe())
+	}
+
+	if _, err := p.consume(Token_RightBrace, "expected '}' to close transition"); err != nil {
+		return nil, false, err
+	}
+
+	return transition, true, nil
 }
 
 // HELPERS -----------------------------------------------------------------------------------------
@@ -69,14 +411,9 @@ func (p *Parser) parseStatechart() (*ASTNodeStatechart, bool, error) {
 // match returns whether the current token matches any of the particular tokens being asked for.
 // If the match is successful, it also consumes the token and advances the stream.
 func (p *Parser) match(ids ...TokenIdentifier) bool {
-	if p.atEnd() {
-		return false
-	}
-
-	peek := p.peek()
 	for _, id := range ids {
 		// If the peeked token matches the type, we advance the stream forward.
-		if peek.id == id {
+		if p.check(id) {
 			p.advance()
 			return true
 		}
@@ -85,12 +422,27 @@ func (p *Parser) match(ids ...TokenIdentifier) bool {
 	return false
 }
 
-// peek looks at the current token pointed by the stream.
-func (p *Parser) peek() *Token {
+// check returns whether the current token is of the given type. Does not consume the token.
+func (p *Parser) check(id TokenIdentifier) bool {
 	if p.atEnd() {
-		panic("already at input's end")
+		return false
 	}
 
+	return p.peek().id == id
+}
+
+// consume advances over the current token if it is of the given type. Otherwise it returns an error
+// with the given message.
+func (p *Parser) consume(id TokenIdentifier, message string) (*Token, error) {
+	if p.check(id) {
+		return p.advance(), nil
+	}
+
+	return nil, p.errorf(p.peek(), "%s, got %s", message, p.peek().describe())
+}
+
+// peek looks at the current token pointed by the stream.
+func (p *Parser) peek() *Token {
 	return p.tokens[p.current]
 }
 
@@ -118,14 +470,117 @@ func (p *Parser) atEnd() bool {
 	return p.peek().id == Token_EOF
 }
 
+func (p *Parser) errorf(token *Token, format string, args ...any) error {
+	return &ParseError{
+		ErrorToken: *token,
+		Message:    fmt.Sprintf(format, args...),
+	}
+}
+
 // Printing ----------------------------------------------------------------------------------------
 
-func (n *ASTNodeRoot) Print() {
+func printIndent(sb *strings.Builder, indent int) {
+	sb.WriteString(strings.Repeat("\t", indent))
+}
+
+func (n *ASTNodeRoot) Print(sb *strings.Builder, indent int) {
 	for _, sc := range n.statecharts {
-		sc.Print()
+		sc.Print(sb, indent)
+	}
+}
+
+func (n *ASTNodeStatechart) Print(sb *strings.Builder, indent int) {
+	printIndent(sb, indent)
+	fmt.Fprintf(sb, "statechart %s {\n", n.name.literal)
+	for _, include := range n.includes {
+		include.Print(sb, indent+1)
+	}
+	for _, trigger := range n.triggers {
+		trigger.Print(sb, indent+1)
+	}
+	for _, state := range n.states {
+		state.Print(sb, indent+1)
+	}
+	printIndent(sb, indent)
+	sb.WriteString("}\n")
+}
+
+func (n *ASTNodeInclude) Print(sb *strings.Builder, indent int) {
+	printIndent(sb, indent)
+	fmt.Fprintf(sb, "include %q\n", n.path.literal)
+}
+
+func (n *ASTNodeTrigger) Print(sb *strings.Builder, indent int) {
+	printIndent(sb, indent)
+	fmt.Fprintf(sb, "trigger %s", n.name.literal)
+	if n.arguments != nil {
+		fmt.Fprintf(sb, "(%q)", n.arguments.literal)
 	}
+	sb.WriteString("\n")
 }
 
-func (n *ASTNodeStatechart) Print() {
-	fmt.Print("statechart { }")
+func (n *ASTNodeState) Print(sb *strings.Builder, indent int) {
+	printIndent(sb, indent)
+	fmt.Fprintf(sb, "state %s {\n", n.name.literal)
+
+	for _, flag := range []struct {
+		name  string
+		value bool
+	}{
+		{"initial", n.initial},
+		{"parallel", n.parallel},
+		{"default_enter", n.defaultEnter},
+		{"default_exit", n.defaultExit},
+		{"final", n.final},
+	} {
+		if flag.value {
+			printIndent(sb, indent+1)
+			sb.WriteString(flag.name + "\n")
+		}
+	}
+
+	if n.history != nil {
+		printIndent(sb, indent+1)
+		fmt.Fprintf(sb, "history %s\n", n.history.literal)
+	}
+
+	for _, reaction := range n.enterReactions {
+		printIndent(sb, indent+1)
+		fmt.Fprintf(sb, "enter_reaction %s\n", reaction.literal)
+	}
+	for _, reaction := range n.exitReactions {
+		printIndent(sb, indent+1)
+		fmt.Fprintf(sb, "exit_reaction %s\n", reaction.literal)
+	}
+
+	for _, child := range n.children {
+		child.Print(sb, indent+1)
+	}
+	for _, transition := range n.transitions {
+		transition.Print(sb, indent+1)
+	}
+	for _, include := range n.includes {
+		include.Print(sb, indent+1)
+	}
+
+	printIndent(sb, indent)
+	sb.WriteString("}\n")
+}
+
+func (n *ASTNodeTransition) Print(sb *strings.Builder, indent int) {
+	printIndent(sb, indent)
+	fmt.Fprintf(sb, "transition %s {", n.target.literal)
+	if n.trigger != nil {
+		fmt.Fprintf(sb, " trigger %s ", n.trigger.literal)
+	}
+	if n.guard != nil {
+		fmt.Fprintf(sb, " guard %s ", n.guard.literal)
+	}
+	for _, action := range n.actions {
+		fmt.Fprintf(sb, " action %s ", action.literal)
+	}
+	if n.internal {
+		sb.WriteString(" internal ")
+	}
+	sb.WriteString("}\n")
 }
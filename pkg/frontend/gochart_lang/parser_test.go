@@ -0,0 +1,241 @@
+package gochart_lang
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/cristiandonosoc/gochart/pkg/frontend"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProcessSimple(t *testing.T) {
+	pos := func(line, column int) frontend.Position {
+		return frontend.Position{File: "testdata/simple.gochart", Line: line, Column: column}
+	}
+
+	want := &frontend.StatechartData{
+		Name: "Simple",
+		Triggers: []*frontend.TriggerData{
+			{Name: "Trigger1", ArgumentsString: "int foo, float bar", Index: 0, Pos: pos(3, 10)},
+			{Name: "Trigger2", Index: 1, Pos: pos(4, 10)},
+		},
+		States: []*frontend.StateData{
+			{Name: "StateA", Initial: true, DefaultEnter: true, DefaultExit: true, Index: 0, Pos: pos(6, 8)},
+			{Name: "StateB", Initial: true, Parent: "StateA", Index: 1, Pos: pos(11, 9)},
+			{Name: "StateC", Parent: "StateA", Index: 2, Pos: pos(16, 9)},
+		},
+		Transitions: []*frontend.TransitionData{
+			{From: "StateB", To: "StateC", Trigger: "Trigger1", Index: 0, Pos: pos(13, 15)},
+		},
+		Pos: pos(2, 12),
+	}
+
+	gf := NewGochartLangFrontend()
+	got, err := gf.ProcessFromFile("testdata/simple.gochart")
+	require.NoError(t, err)
+	assert.Equal(t, want, got)
+}
+
+func TestProcessReactionsAndNullTransitions(t *testing.T) {
+	input := `
+statechart Reactions {
+	trigger Hit("int damage")
+	state Alive {
+		initial
+		enter_reaction Hit
+		exit_reaction Hit
+		transition Dead { guard IsOutOfHealth action Ragdoll action DropLoot }
+		transition Alive { trigger Hit internal action Flinch }
+	}
+	state Dead {}
+}`
+
+	gf := NewGochartLangFrontend()
+	got, err := gf.Process(strings.NewReader(input))
+	require.NoError(t, err)
+
+	require.Len(t, got.States, 2)
+	assert.Equal(t, []string{"Hit"}, got.States[0].EnterReactionTriggers)
+	assert.Equal(t, []string{"Hit"}, got.States[0].ExitReactionTriggers)
+
+	require.Len(t, got.Transitions, 2)
+	assert.Equal(t, "Alive", got.Transitions[0].From)
+	assert.Equal(t, "Dead", got.Transitions[0].To)
+	assert.Equal(t, "", got.Transitions[0].Trigger)
+	assert.Equal(t, "IsOutOfHealth", got.Transitions[0].Guard)
+	assert.Equal(t, []string{"Ragdoll", "DropLoot"}, got.Transitions[0].Actions)
+	assert.False(t, got.Transitions[0].Internal)
+
+	assert.Equal(t, "Alive", got.Transitions[1].To)
+	assert.Equal(t, "Hit", got.Transitions[1].Trigger)
+	assert.True(t, got.Transitions[1].Internal)
+	assert.Equal(t, []string{"Flinch"}, got.Transitions[1].Actions)
+}
+
+func TestProcessParallelAndHistory(t *testing.T) {
+	input := `
+statechart Player {
+	state Alive {
+		initial
+		parallel
+		state Movement {
+			state Idle { initial }
+			state MovementHistory { history deep }
+		}
+		state Weapon {
+			state Ready { initial }
+		}
+	}
+}`
+
+	gf := NewGochartLangFrontend()
+	got, err := gf.Process(strings.NewReader(input))
+	require.NoError(t, err)
+
+	require.Len(t, got.States, 6)
+	assert.True(t, got.States[0].Parallel)
+	assert.Equal(t, "MovementHistory", got.States[3].Name)
+	assert.Equal(t, "Movement", got.States[3].Parent)
+	assert.Equal(t, "deep", got.States[3].History)
+}
+
+func TestProcessIncludes(t *testing.T) {
+	pos := func(file string, line, column int) frontend.Position {
+		return frontend.Position{File: "testdata/include/" + file, Line: line, Column: column}
+	}
+
+	// The shared triggers are included by both the player and its movement, but merged only once.
+	want := &frontend.StatechartData{
+		Name: "Player",
+		Triggers: []*frontend.TriggerData{
+			{Name: "Move", ArgumentsString: "float speed", Index: 0, Pos: pos("triggers.gochart", 3, 10)},
+			{Name: "Stop", Index: 1, Pos: pos("triggers.gochart", 4, 10)},
+			{Name: "Die", Index: 2, Pos: pos("player.gochart", 3, 10)},
+		},
+		States: []*frontend.StateData{
+			{Name: "Alive", Initial: true, Index: 0, Pos: pos("player.gochart", 5, 8)},
+			{Name: "Idle", Initial: true, Parent: "Alive", Index: 1, Pos: pos("movement.gochart", 4, 8)},
+			{Name: "Walking", Parent: "Alive", Index: 2, Pos: pos("movement.gochart", 8, 8)},
+			{Name: "Dead", Final: true, Index: 3, Pos: pos("player.gochart", 11, 8)},
+		},
+		Transitions: []*frontend.TransitionData{
+			{From: "Alive", To: "Dead", Trigger: "Die", Index: 0, Pos: pos("player.gochart", 8, 14)},
+			{From: "Idle", To: "Walking", Trigger: "Move", Index: 1, Pos: pos("movement.gochart", 6, 14)},
+			{From: "Walking", To: "Idle", Trigger: "Stop", Index: 2, Pos: pos("movement.gochart", 9, 14)},
+		},
+		Pos: pos("player.gochart", 1, 12),
+	}
+
+	gf := NewGochartLangFrontend()
+	got, err := gf.ProcessFromFile("testdata/include/player.gochart")
+	require.NoError(t, err)
+	assert.Equal(t, want, got)
+}
+
+func TestProcessIncludeErrors(t *testing.T) {
+	testcases := []struct {
+		path    string
+		wantErr string
+	}{
+		{
+			path: "testdata/include/cycle_a.gochart",
+			wantErr: `line 2, char 10: including "cycle_b.gochart": testdata/include/cycle_b.gochart: ` +
+				`line 2, char 10: including "cycle_a.gochart": include cycle: ` +
+				`testdata/include/cycle_a.gochart -> testdata/include/cycle_b.gochart -> testdata/include/cycle_a.gochart`,
+		},
+		{
+			path: "testdata/include/broken.gochart",
+			wantErr: `line 3, char 11: including "broken_child.gochart": testdata/include/broken_child.gochart: ` +
+				`parsing: parsing statechart node: statechart "BrokenChild": parsing state: ` +
+				`line 2, char 16: state "Child": unknown state attribute "intial"`,
+		},
+		{
+			// The second include would leave the states of movement out of Enemy.
+			path: "testdata/include/two_parents.gochart",
+			wantErr: `line 7, char 11: including "movement.gochart": "testdata/include/movement.gochart" is already included at ` +
+				`testdata/include/two_parents.gochart:4:11, and the states it defines can only be included once`,
+		},
+	}
+
+	for _, tc := range testcases {
+		gf := NewGochartLangFrontend()
+		_, err := gf.ProcessFromFile(tc.path)
+		if assert.Error(t, err, tc.path) {
+			assert.Contains(t, err.Error(), tc.wantErr, tc.path)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	testcases := []struct {
+		input   string
+		wantErr string
+	}{
+		{
+			input:   ``,
+			wantErr: "expected at least one statechart",
+		},
+		{
+			input:   `state Foo {}`,
+			wantErr: "expected at least one statechart",
+		},
+		{
+			input:   `statechart {}`,
+			wantErr: "line 1, char 12: expected statechart name, got '{'",
+		},
+		{
+			input:   `statechart Foo { state Bar }`,
+			wantErr: "expected '{' after state name, got '}'",
+		},
+		{
+			input:   `statechart Foo { state Bar { intial } }`,
+			wantErr: `unknown state attribute "intial"`,
+		},
+		{
+			input:   `statechart Foo { trigger Bar("int foo" }`,
+			wantErr: "expected ')' to close trigger arguments",
+		},
+		{
+			input:   `statechart Foo { state Bar { transition Baz { trigger A trigger B } } }`,
+			wantErr: `transition to "Baz" already has a trigger`,
+		},
+		{
+			input:   `statechart Foo { state Bar {`,
+			wantErr: "expected '}' to close state, got end of input",
+		},
+		{
+			input:   `statechart Foo { state Bar { history deep history shallow } }`,
+			wantErr: `state "Bar": history defined twice`,
+		},
+		{
+			input:   `statechart Foo { state Bar { transition Baz { guard A guard B } } }`,
+			wantErr: `transition to "Baz" already has a guard`,
+		},
+		{
+			input:   `statechart Foo {} statechart Bar {}`,
+			wantErr: "only one statechart per input is supported",
+		},
+		{
+			input:   `statechart Foo { include Bar }`,
+			wantErr: "expected path of the included file, got identifier \"Bar\"",
+		},
+		{
+			input:   `statechart Foo { include "testdata/missing.gochart" }`,
+			wantErr: `line 1, char 26: including "testdata/missing.gochart": reading "testdata/missing.gochart"`,
+		},
+		{
+			input:   `statechart Foo {} }`,
+			wantErr: "expected statechart, got '}'",
+		},
+	}
+
+	for _, tc := range testcases {
+		gf := NewGochartLangFrontend()
+		_, err := gf.Process(strings.NewReader(tc.input))
+		if assert.Error(t, err, "input: %s", tc.input) {
+			assert.Contains(t, err.Error(), tc.wantErr, "input: %s", tc.input)
+		}
+	}
+}
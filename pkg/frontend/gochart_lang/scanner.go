@@ -51,25 +51,49 @@ func NewScanner() *Scanner {
 			"state":      Token_KeywordState,
 			"transition": Token_KeywordTransition,
 			"trigger":    Token_KeywordTrigger,
+			"include":    Token_KeywordInclude,
 		},
 	}
 }
 
 func (s *Scanner) reset() {
 	s.start = 0
+	s.currentByteCount = 0
 	s.currentRuneCount = 0
+	s.currentRuneCountInLine = 0
 	s.totalRunes = 0
 	s.line = 1
 }
 
+// Scan reads the whole input, parses it against the gochart_lang grammar and lowers the result into
+// the frontend representation of the statechart. Included files are relative to the working
+// directory.
 func (s *Scanner) Scan(r io.Reader) (*frontend.StatechartData, []error) {
+	return s.scan(r, frontend.NewIncluder())
+}
+
+// scan is |Scan| with |includer| resolving the files included by the input.
+func (s *Scanner) scan(r io.Reader, includer *frontend.Includer) (*frontend.StatechartData, []error) {
 	// Get all the tokens in this input.
-	_, errors := s.gatherTokens(r)
+	tokens, errors := s.gatherTokens(r)
 	if errors != nil {
 		return nil, errors
 	}
 
-	return &frontend.StatechartData{}, nil
+	p := &Parser{}
+	root, err := p.Parse(tokens)
+	if err != nil {
+		return nil, []error{fmt.Errorf("parsing: %w", err)}
+	}
+
+	// For now we only support one statechart per input.
+	if len(root.statecharts) > 1 {
+		extra := root.statecharts[1].name
+		return nil, []error{fmt.Errorf("line %d, char %d: only one statechart per input is supported, found %q",
+			extra.line, extra.char, extra.literal)}
+	}
+
+	return root.statecharts[0].toStatechartData(includer)
 }
 
 func (s *Scanner) gatherTokens(r io.Reader) ([]*Token, []error) {
@@ -97,6 +121,16 @@ func (s *Scanner) gatherTokens(r io.Reader) ([]*Token, []error) {
 		tokens = append(tokens, token)
 	}
 
+	// The token stream is always terminated by an EOF. It might have been already added if the input
+	// ended in ignored input (eg. whitespace or comments).
+	if len(tokens) == 0 || tokens[len(tokens)-1].id != Token_EOF {
+		tokens = append(tokens, &Token{
+			id:   Token_EOF,
+			line: s.line,
+			char: s.currentRuneCountInLine,
+		})
+	}
+
 	return tokens, errors
 }
 
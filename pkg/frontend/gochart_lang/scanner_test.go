@@ -44,6 +44,8 @@ func TestGatherTokens(t *testing.T) {
 
 		{id: Token_Identifier, literal: "aabb", line: 8, char: 39},
 		{id: Token_Identifier, literal: "a0123456789b", line: 8, char: 44},
+
+		{id: Token_EOF, line: 8, char: 55},
 	}
 
 	s := NewScanner()
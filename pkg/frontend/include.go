@@ -0,0 +1,106 @@
+package frontend
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Includer keeps track of the files read while processing a statechart that is split across files,
+// so that frontends supporting includes resolve them the same way:
+//
+//   - Included paths are relative to the file that includes them. When the including input is not a
+//     file, they are relative to the working directory.
+//   - A file that is already being processed cannot be included again down its own include chain.
+//     That is a cycle and an error that names the chain (eg. "a.yaml -> b.yaml -> a.yaml").
+//   - Every file is merged at most once per statechart. Later includes of an already included file
+//     are ignored, so a shared trigger set can be included by several sub-charts. A file that defines
+//     states cannot be included again though, as its states would be left out of the second place
+//     that includes it. That is an error that names where the file was first included.
+//
+// Errors within an included file are prefixed with its path. As each frontend prefixes the error of
+// an include with where that include was, the final error walks the whole including file chain.
+type Includer struct {
+	// chain holds the files being processed, outermost first.
+	chain []string
+
+	// included holds every file read so far, by absolute path.
+	included map[string]*includedFile
+}
+
+type includedFile struct {
+	// site is where the file was first included.
+	site Position
+
+	// hasStates is whether the file defines any state.
+	hasStates bool
+}
+
+func NewIncluder() *Includer {
+	return &Includer{
+		included: map[string]*includedFile{},
+	}
+}
+
+// Include reads the file at |path| and passes its contents to |process|, which is meant to be the
+// frontend processing it (with this same includer for any nested include). |site| is where the
+// include is within the including file, which gets filled in. Returns nil data if the file was
+// already included. The elements of the returned data have the file set in their position.
+func (inc *Includer) Include(path string, site Position, process func(r io.Reader) (*StatechartData, error)) (*StatechartData, error) {
+	// The first file is the input itself, which is not included by any other.
+	nested := len(inc.chain) > 0
+	if nested {
+		including := inc.chain[len(inc.chain)-1]
+		if site.File == "" {
+			site.File = including
+		}
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(filepath.Dir(including), path)
+		}
+	}
+	path = filepath.Clean(path)
+
+	key, err := filepath.Abs(path)
+	if err != nil {
+		return nil, fmt.Errorf("resolving %q: %w", path, err)
+	}
+
+	for i, including := range inc.chain {
+		if abs, err := filepath.Abs(including); err == nil && abs == key {
+			cycle := append(append([]string{}, inc.chain[i:]...), path)
+			return nil, fmt.Errorf("include cycle: %s", strings.Join(cycle, " -> "))
+		}
+	}
+
+	if previous, ok := inc.included[key]; ok {
+		if previous.hasStates {
+			return nil, fmt.Errorf("%q is already included at %s, and the states it defines can only be included once",
+				path, previous.site)
+		}
+		return nil, nil
+	}
+	record := &includedFile{site: site}
+	inc.included[key] = record
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading %q: %w", path, err)
+	}
+
+	inc.chain = append(inc.chain, path)
+	scdata, err := process(bytes.NewReader(data))
+	inc.chain = inc.chain[:len(inc.chain)-1]
+	if err != nil {
+		if nested {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		return nil, err
+	}
+
+	record.hasStates = len(scdata.States) > 0
+	scdata.SetFile(path)
+	return scdata, nil
+}
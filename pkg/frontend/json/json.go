@@ -0,0 +1,169 @@
+// Package json is a frontend that reads the statechart data as JSON, with the same shape as the flat
+// form of the yaml frontend. It is meant for tools that emit statecharts programmatically. The shape is published as
+// a JSON Schema (see Schema) so that editors can validate and autocomplete the definitions.
+package json
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/cristiandonosoc/gochart/pkg/frontend"
+)
+
+func NewJsonFrontend() *jsonFrontend {
+	return &jsonFrontend{}
+}
+
+var _ frontend.GochartFrontend = (*jsonFrontend)(nil)
+
+type jsonFrontend struct {
+}
+
+func (jf *jsonFrontend) Process(r io.Reader) (*frontend.StatechartData, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("reading input reader: %w", err)
+	}
+
+	// Unknown fields are most likely typos, which would otherwise be silently ignored.
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+
+	var scdata frontend.StatechartData
+	if err := decoder.Decode(&scdata); err != nil {
+		return nil, fmt.Errorf("unmarshalling json: %w", locateError(data, err))
+	}
+	if decoder.More() {
+		return nil, fmt.Errorf("unmarshalling json: unexpected data after the statechart")
+	}
+
+	if err := locateElements(data, &scdata); err != nil {
+		return nil, fmt.Errorf("locating elements: %w", err)
+	}
+
+	return &scdata, nil
+}
+
+func (jf *jsonFrontend) ProcessFromFile(path string) (*frontend.StatechartData, error) {
+	return frontend.ProcessFromFile(jf, path)
+}
+
+// locateError adds the line and column to the errors that only come with the offset.
+func locateError(data []byte, err error) error {
+	var offset int64
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &syntaxErr):
+		offset = syntaxErr.Offset
+	case errors.As(err, &typeErr):
+		offset = typeErr.Offset
+	default:
+		return err
+	}
+
+	// The offsets point right after the offending character or value.
+	if offset > 0 {
+		offset--
+	}
+	pos := position(data, offset)
+	return fmt.Errorf("line %d, char %d: %w", pos.Line, pos.Column, err)
+}
+
+// position returns the line and column of |offset| within |data|.
+func position(data []byte, offset int64) frontend.Position {
+	if offset > int64(len(data)) {
+		offset = int64(len(data))
+	}
+	before := data[:offset]
+	return frontend.Position{
+		Line:   bytes.Count(before, []byte("\n")) + 1,
+		Column: len(before) - bytes.LastIndexByte(before, '\n'),
+	}
+}
+
+// locateElements sets the position and index of the triggers, states and transitions of |scdata|,
+// which was decoded from |data|. The decoder does not track positions, so we walk the tokens again:
+// the statechart is placed at its name, and the elements at the object that defines them.
+func locateElements(data []byte, scdata *frontend.StatechartData) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	if _, err := decoder.Token(); err != nil {
+		return err
+	}
+
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return err
+		}
+		key, _ := token.(string)
+
+		var offsets []int64
+		switch key {
+		case "triggers", "states", "transitions":
+			offsets, err = arrayOffsets(data, decoder)
+		default:
+			offset := valueOffset(data, decoder)
+			if key == "name" {
+				scdata.Pos = position(data, offset)
+			}
+			err = decoder.Decode(&json.RawMessage{})
+		}
+		if err != nil {
+			return err
+		}
+
+		for i, offset := range offsets {
+			switch {
+			case key == "triggers" && i < len(scdata.Triggers):
+				scdata.Triggers[i].Index = i
+				scdata.Triggers[i].Pos = position(data, offset)
+			case key == "states" && i < len(scdata.States):
+				scdata.States[i].Index = i
+				scdata.States[i].Pos = position(data, offset)
+			case key == "transitions" && i < len(scdata.Transitions):
+				scdata.Transitions[i].Index = i
+				scdata.Transitions[i].Pos = position(data, offset)
+			}
+		}
+	}
+
+	return nil
+}
+
+// arrayOffsets consumes the array that is next in |decoder|, and returns where each of its items
+// begins. A null is an empty array.
+func arrayOffsets(data []byte, decoder *json.Decoder) ([]int64, error) {
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+	if token == nil {
+		return nil, nil
+	}
+
+	var offsets []int64
+	for decoder.More() {
+		offsets = append(offsets, valueOffset(data, decoder))
+		if err := decoder.Decode(&json.RawMessage{}); err != nil {
+			return nil, err
+		}
+	}
+
+	// The closing bracket.
+	_, err = decoder.Token()
+	return offsets, err
+}
+
+// valueOffset returns where the value that is next in |decoder| begins. The offset of the decoder is
+// right after the previous token, so we skip what separates them.
+func valueOffset(data []byte, decoder *json.Decoder) int64 {
+	offset := decoder.InputOffset()
+	for offset < int64(len(data)) && bytes.IndexByte([]byte(" \t\r\n,:"), data[offset]) >= 0 {
+		offset++
+	}
+	return offset
+}
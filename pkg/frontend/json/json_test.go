@@ -0,0 +1,90 @@
+package json
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/cristiandonosoc/gochart/pkg/frontend"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProcessSimple(t *testing.T) {
+	pos := func(line, column int) frontend.Position {
+		return frontend.Position{File: "testdata/simple.json", Line: line, Column: column}
+	}
+
+	want := &frontend.StatechartData{
+		Name: "Simple",
+		Triggers: []*frontend.TriggerData{
+			{Name: "Trigger1", ArgumentsString: "int foo, float bar", Index: 0, Pos: pos(4, 5)},
+			{Name: "Trigger2", Index: 1, Pos: pos(5, 5)},
+		},
+		States: []*frontend.StateData{
+			{Name: "StateA", Initial: true, DefaultEnter: true, DefaultExit: true, Index: 0, Pos: pos(8, 5)},
+			{Name: "StateB", Initial: true, Parent: "StateA", Index: 1, Pos: pos(9, 5)},
+			{Name: "StateC", Parent: "StateA", Index: 2, Pos: pos(10, 5)},
+		},
+		Transitions: []*frontend.TransitionData{
+			{From: "StateB", To: "StateC", Trigger: "Trigger1", Actions: []string{"Log"}, Index: 0, Pos: pos(13, 5)},
+		},
+		Pos: pos(2, 11),
+	}
+
+	jf := NewJsonFrontend()
+	got, err := jf.ProcessFromFile("testdata/simple.json")
+	require.NoError(t, err)
+	assert.Equal(t, want, got)
+}
+
+func TestProcessErrors(t *testing.T) {
+	testcases := []struct {
+		name    string
+		input   string
+		wantErr string
+	}{
+		{
+			name:    "unknown field",
+			input:   `{"name": "Foo", "states": [{"name": "A", "intial": true}]}`,
+			wantErr: `json: unknown field "intial"`,
+		},
+		{
+			name:    "syntax error",
+			input:   "{\n  \"name\": \"Foo\",\n  \"states\": [}\n}",
+			wantErr: "line 3, char 14: invalid character '}'",
+		},
+		{
+			name:    "wrong type",
+			input:   "{\n  \"name\": \"Foo\",\n  \"states\": [{\"name\": \"A\", \"initial\": \"yes\"}]\n}",
+			wantErr: "line 3, char 43: json: cannot unmarshal string into Go struct field",
+		},
+		{
+			name:    "trailing data",
+			input:   `{"name": "Foo"} {"name": "Bar"}`,
+			wantErr: "unexpected data after the statechart",
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			jf := NewJsonFrontend()
+			_, err := jf.Process(strings.NewReader(tc.input))
+			if assert.Error(t, err) {
+				assert.Contains(t, err.Error(), tc.wantErr)
+			}
+		})
+	}
+}
+
+// TestSchemaIsUpToDate checks that the published schema matches the statechart data.
+// To update it, run `gochart -schema > pkg/frontend/json/statechart.schema.json`.
+func TestSchemaIsUpToDate(t *testing.T) {
+	want, err := Schema()
+	require.NoError(t, err)
+
+	got, err := os.ReadFile("statechart.schema.json")
+	require.NoError(t, err)
+	assert.Equal(t, string(want), string(got))
+}
@@ -0,0 +1,102 @@
+package json
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/cristiandonosoc/gochart/pkg/frontend"
+)
+
+// SchemaVersion is the JSON Schema draft the schema is written for, which is the one most editors
+// support.
+const SchemaVersion = "http://json-schema.org/draft-07/schema#"
+
+// Schema returns the JSON Schema of the statechart definitions read by this frontend. It does not
+// describe the yaml definitions: besides the flat form, the yaml frontend accepts nested "states" and
+// "transitions" and "include", which this frontend does not.
+//
+// The schema is derived from frontend.StatechartData: properties come from the json tags, and the
+// jsonschema tags mark the "required" ones and the "enum=a|b" values of strings.
+func Schema() ([]byte, error) {
+	schema, err := schemaFor(reflect.TypeOf(frontend.StatechartData{}))
+	if err != nil {
+		return nil, fmt.Errorf("building schema: %w", err)
+	}
+	schema["$schema"] = SchemaVersion
+	schema["title"] = "Gochart statechart"
+
+	data, err := json.MarshalIndent(schema, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("marshalling schema: %w", err)
+	}
+	return append(data, '\n'), nil
+}
+
+func schemaFor(t reflect.Type) (map[string]any, error) {
+	switch t.Kind() {
+	case reflect.Pointer:
+		return schemaFor(t.Elem())
+	case reflect.String:
+		return map[string]any{"type": "string"}, nil
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}, nil
+	case reflect.Int:
+		return map[string]any{"type": "integer"}, nil
+	case reflect.Slice:
+		items, err := schemaFor(t.Elem())
+		if err != nil {
+			return nil, err
+		}
+		return map[string]any{"type": "array", "items": items}, nil
+	case reflect.Struct:
+		return schemaForStruct(t)
+	}
+
+	return nil, fmt.Errorf("type %s is not supported", t)
+}
+
+func schemaForStruct(t reflect.Type) (map[string]any, error) {
+	properties := make(map[string]any)
+	var required []string
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" || !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		property, err := schemaFor(field.Type)
+		if err != nil {
+			return nil, fmt.Errorf("field %s.%s: %w", t.Name(), field.Name, err)
+		}
+
+		for _, option := range strings.Split(field.Tag.Get("jsonschema"), ",") {
+			switch {
+			case option == "":
+			case option == "required":
+				required = append(required, name)
+			case strings.HasPrefix(option, "enum="):
+				property["enum"] = strings.Split(strings.TrimPrefix(option, "enum="), "|")
+			default:
+				return nil, fmt.Errorf("field %s.%s: unknown jsonschema option %q", t.Name(), field.Name, option)
+			}
+		}
+
+		properties[name] = property
+	}
+
+	schema := map[string]any{
+		"type":                 "object",
+		"properties":           properties,
+		"additionalProperties": false,
+	}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema, nil
+}
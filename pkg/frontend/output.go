@@ -6,45 +6,128 @@ import (
 
 // StatechartData is all the information a frontend needs to output about a statechart.
 // This will be consumed by the |ir| package and validated.
+//
+// The yaml and json tags define how the statechart is written by the yaml and json frontends, and
+// the jsonschema tags add what the JSON Schema of that shape cannot infer from the types (see the
+// json frontend): "required" fields and the "enum=a|b" values a string can take.
 type StatechartData struct {
-	Name        string            `yaml:"name"`
-	Triggers    []*TriggerData    `yaml:"triggers"`
-	States      []*StateData      `yaml:"states"`
-	Transitions []*TransitionData `yaml:"transitions"`
+	Name        string            `yaml:"name" json:"name" jsonschema:"required"`
+	Triggers    []*TriggerData    `yaml:"triggers" json:"triggers"`
+	States      []*StateData      `yaml:"states" json:"states"`
+	Transitions []*TransitionData `yaml:"transitions" json:"transitions"`
+
+	// Pos is where the statechart was defined.
+	Pos Position `yaml:"-" json:"-"`
+}
+
+// SetFile marks |path| as the file the elements of the statechart were read from. Elements that
+// already have a file, like the ones merged from an included file, keep it.
+func (scdata *StatechartData) SetFile(path string) {
+	setFile := func(pos *Position) {
+		if pos.File == "" {
+			pos.File = path
+		}
+	}
+
+	setFile(&scdata.Pos)
+	for _, tdata := range scdata.Triggers {
+		setFile(&tdata.Pos)
+	}
+	for _, sdata := range scdata.States {
+		setFile(&sdata.Pos)
+	}
+	for _, tdata := range scdata.Transitions {
+		setFile(&tdata.Pos)
+	}
+}
+
+// Merge appends the triggers, states and transitions of |other|, as read from an included file.
+// The states at the top level of |other| are nested within |parent|, unless it is empty. The name
+// of |other| is ignored.
+func (scdata *StatechartData) Merge(other *StatechartData, parent string) {
+	for _, tdata := range other.Triggers {
+		tdata.Index = len(scdata.Triggers)
+		scdata.Triggers = append(scdata.Triggers, tdata)
+	}
+	for _, sdata := range other.States {
+		if sdata.Parent == "" {
+			sdata.Parent = parent
+		}
+		sdata.Index = len(scdata.States)
+		scdata.States = append(scdata.States, sdata)
+	}
+	for _, tdata := range other.Transitions {
+		tdata.Index = len(scdata.Transitions)
+		scdata.Transitions = append(scdata.Transitions, tdata)
+	}
 }
 
 type TriggerData struct {
-	Name            string `yaml:"name"`
-	ArgumentsString string `yaml:"arguments_string"`
+	Name            string `yaml:"name" json:"name" jsonschema:"required"`
+	ArgumentsString string `yaml:"arguments_string" json:"arguments_string"`
 
 	// Index represents in what order it was found.
-	Index int
+	Index int `yaml:"-" json:"-"`
+
+	// Pos is where it was defined.
+	Pos Position `yaml:"-" json:"-"`
 }
 
 type StateData struct {
-	Name    string `yaml:"name"`
-	Initial bool   `yaml:"initial"`
-	Parent  string `yaml:"parent"`
+	Name    string `yaml:"name" json:"name" jsonschema:"required"`
+	Initial bool   `yaml:"initial" json:"initial"`
+	Parent  string `yaml:"parent" json:"parent"`
+
+	// Parallel marks that all the children of this state are orthogonal regions, which are active at
+	// the same time.
+	Parallel bool `yaml:"parallel" json:"parallel"`
 
-	DefaultEnter          bool     `yaml:"default_enter"`
-	EnterReactionTriggers []string `yaml:"enter_reaction_triggers"`
+	// History marks this state as a history pseudo-state of its parent. Can be "shallow" or "deep".
+	// Transitions that target it resume the parent from the last active substate.
+	History string `yaml:"history" json:"history" jsonschema:"enum=shallow|deep"`
 
-	DefaultExit          bool     `yaml:"default_exit"`
-	ExitReactionTriggers []string `yaml:"exit_reaction_triggers"`
+	// Final marks this state as one that is expected to never be left, so it is not reported as a
+	// dead end.
+	Final bool `yaml:"final" json:"final"`
+
+	DefaultEnter          bool     `yaml:"default_enter" json:"default_enter"`
+	EnterReactionTriggers []string `yaml:"enter_reaction_triggers" json:"enter_reaction_triggers"`
+
+	DefaultExit          bool     `yaml:"default_exit" json:"default_exit"`
+	ExitReactionTriggers []string `yaml:"exit_reaction_triggers" json:"exit_reaction_triggers"`
 
 	// Index represents in what order it was found.
-	Index int
+	Index int `yaml:"-" json:"-"`
+
+	// Pos is where it was defined.
+	Pos Position `yaml:"-" json:"-"`
 }
 
 type TransitionData struct {
-	From    string `yaml:"from"`
-	To      string `yaml:"to"`
-	Trigger string `yaml:"trigger"`
+	From    string `yaml:"from" json:"from" jsonschema:"required"`
+	To      string `yaml:"to" json:"to" jsonschema:"required"`
+	Trigger string `yaml:"trigger" json:"trigger"`
+
+	// Guard is the name of the predicate that has to pass for this transition to be taken.
+	Guard string `yaml:"guard" json:"guard"`
+
+	// Actions are the names of the callbacks to run when taking this transition, in order.
+	Actions []string `yaml:"actions" json:"actions"`
+
+	// Internal transitions do not exit their source state. They can only target the source itself or
+	// one of its descendants.
+	Internal bool `yaml:"internal" json:"internal"`
 
 	// Index represents in what order it was found.
-	Index int
+	Index int `yaml:"-" json:"-"`
+
+	// Pos is where it was defined.
+	Pos Position `yaml:"-" json:"-"`
 }
 
 func (tdata *TransitionData) String() string {
+	if tdata.Guard != "" {
+		return fmt.Sprintf("transition %s [%s]: %s > %s", tdata.Trigger, tdata.Guard, tdata.From, tdata.To)
+	}
 	return fmt.Sprintf("transition %s: %s > %s", tdata.Trigger, tdata.From, tdata.To)
 }
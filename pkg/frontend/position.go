@@ -0,0 +1,37 @@
+package frontend
+
+import (
+	"fmt"
+)
+
+// Position points at where an element was defined within the input of a frontend, so that later
+// stages can report problems in terms the user can find. Lines and columns start at 1. Frontends
+// that cannot track where an element comes from leave them at 0.
+type Position struct {
+	File   string
+	Line   int
+	Column int
+}
+
+// IsValid returns whether the position points to an actual line of the input.
+func (p Position) IsValid() bool {
+	return p.Line > 0
+}
+
+func (p Position) String() string {
+	file := p.File
+	if file == "" {
+		file = "<input>"
+	}
+
+	switch {
+	case p.Line > 0 && p.Column > 0:
+		return fmt.Sprintf("%s:%d:%d", file, p.Line, p.Column)
+	case p.Line > 0:
+		return fmt.Sprintf("%s:%d", file, p.Line)
+	case p.File != "":
+		return p.File
+	}
+
+	return ""
+}
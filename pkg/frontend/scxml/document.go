@@ -0,0 +1,91 @@
+package scxml
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/cristiandonosoc/gochart/pkg/frontend"
+)
+
+// Namespace is the XML namespace of SCXML documents.
+const Namespace = "http://www.w3.org/2005/07/scxml"
+
+// GochartNamespace is the XML namespace of the gochart specific attributes, which hold what SCXML
+// cannot express.
+const GochartNamespace = "https://github.com/cristiandonosoc/gochart"
+
+// element is a node of the XML document, with the position it was found at.
+type element struct {
+	name string
+
+	// foreign marks elements from other namespaces, like editor metadata, which are ignored.
+	foreign bool
+
+	// attrs holds the attributes without namespace by name, and the gochart specific ones by name
+	// prefixed with "gochart:".
+	attrs map[string]string
+
+	children []*element
+	pos      frontend.Position
+}
+
+func (e *element) attr(name string) string {
+	return e.attrs[name]
+}
+
+// readDocument reads the XML tree of the document and returns its root element. Text content is
+// dropped, as no supported element uses it.
+func readDocument(r io.Reader) (*element, error) {
+	decoder := xml.NewDecoder(r)
+
+	var root *element
+	var stack []*element
+	for {
+		// The position before reading a token is where that token starts.
+		line, column := decoder.InputPos()
+
+		token, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("parsing xml: %w", err)
+		}
+
+		switch token := token.(type) {
+		case xml.StartElement:
+			e := &element{
+				name:    token.Name.Local,
+				foreign: token.Name.Space != "" && token.Name.Space != Namespace,
+				attrs:   make(map[string]string),
+				pos:     frontend.Position{Line: line, Column: column},
+			}
+			for _, attr := range token.Attr {
+				switch attr.Name.Space {
+				case "":
+					e.attrs[attr.Name.Local] = attr.Value
+				case GochartNamespace:
+					e.attrs["gochart:"+attr.Name.Local] = attr.Value
+				}
+			}
+
+			if len(stack) == 0 {
+				root = e
+			} else {
+				parent := stack[len(stack)-1]
+				parent.children = append(parent.children, e)
+			}
+			stack = append(stack, e)
+		case xml.EndElement:
+			stack = stack[:len(stack)-1]
+		}
+	}
+
+	if root == nil {
+		return nil, fmt.Errorf("no root element found")
+	}
+
+	return root, nil
+}
@@ -0,0 +1,302 @@
+package scxml
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/cristiandonosoc/gochart/pkg/frontend"
+)
+
+// LOWERING ----------------------------------------------------------------------------------------
+
+// lowerer converts the SCXML tree into the frontend representation. It collects all the problems
+// instead of stopping at the first one, so that the user can fix the document in one go.
+type lowerer struct {
+	scdata   *frontend.StatechartData
+	triggers map[string]bool
+	errs     []error
+}
+
+func (l *lowerer) errorf(e *element, format string, args ...any) {
+	msg := fmt.Sprintf(format, args...)
+	l.errs = append(l.errs, fmt.Errorf("line %d, char %d: %s", e.pos.Line, e.pos.Column, msg))
+}
+
+func (l *lowerer) unsupported(e *element, within *element) {
+	l.errorf(e, "<%s> within <%s> is not supported", e.name, within.name)
+}
+
+func lowerDocument(root *element) (*frontend.StatechartData, []error) {
+	l := &lowerer{
+		scdata:   &frontend.StatechartData{Pos: root.pos},
+		triggers: make(map[string]bool),
+	}
+
+	if root.name != "scxml" || root.foreign {
+		l.errorf(root, "expected <scxml> as the root element, got <%s>", root.name)
+		return nil, l.errs
+	}
+
+	l.scdata.Name = root.attr("name")
+	if l.scdata.Name == "" {
+		l.errorf(root, "<scxml> requires a name attribute, as it is the name of the statechart")
+	}
+
+	var children []*frontend.StateData
+	for _, child := range root.children {
+		switch {
+		case child.foreign:
+			continue
+		case isStateElement(child.name):
+			children = append(children, l.lowerState(child, ""))
+		default:
+			l.unsupported(child, root)
+		}
+	}
+	l.markInitial(root, children, nil)
+
+	if l.errs != nil {
+		return nil, l.errs
+	}
+
+	return l.scdata, nil
+}
+
+func isStateElement(name string) bool {
+	switch name {
+	case "state", "parallel", "final", "history":
+		return true
+	}
+	return false
+}
+
+func (l *lowerer) lowerState(e *element, parent string) *frontend.StateData {
+	sdata := &frontend.StateData{
+		Name:   e.attr("id"),
+		Parent: parent,
+		Index:  len(l.scdata.States),
+		Pos:    e.pos,
+	}
+	if sdata.Name == "" {
+		l.errorf(e, "<%s> requires an id attribute, as it is the name of the state", e.name)
+	}
+	l.scdata.States = append(l.scdata.States, sdata)
+
+	switch e.name {
+	case "parallel":
+		sdata.Parallel = true
+	case "final":
+		sdata.Final = true
+	case "history":
+		// SCXML histories are shallow unless specified.
+		sdata.History = "shallow"
+		if kind := e.attr("type"); kind != "" {
+			sdata.History = kind
+		}
+	}
+
+	var children []*frontend.StateData
+	var initial *element
+	for _, child := range e.children {
+		switch {
+		case child.foreign:
+			continue
+		case e.name == "history" && child.name == "transition":
+			// The IR enters the parent by default when there is no history recorded yet.
+			l.errorf(child, "default transitions of <history> are not supported")
+		case e.name == "history":
+			l.unsupported(child, e)
+		case isStateElement(child.name) && e.name != "final":
+			children = append(children, l.lowerState(child, sdata.Name))
+		case child.name == "initial" && e.name == "state":
+			initial = child
+		case child.name == "transition" && e.name != "final":
+			l.lowerTransition(child, sdata.Name)
+		case child.name == "onentry":
+			sdata.EnterReactionTriggers = append(sdata.EnterReactionTriggers,
+				l.lowerReactions(child, &sdata.DefaultEnter)...)
+		case child.name == "onexit":
+			sdata.ExitReactionTriggers = append(sdata.ExitReactionTriggers,
+				l.lowerReactions(child, &sdata.DefaultExit)...)
+		default:
+			l.unsupported(child, e)
+		}
+	}
+
+	// Regions of a parallel state are all entered, so there is no initial one to mark.
+	if e.name == "state" {
+		l.markInitial(e, children, initial)
+	}
+
+	return sdata
+}
+
+// markInitial marks the initial child of |e|, given either by its initial attribute or its
+// <initial> element. Without any, SCXML enters the first child in document order.
+func (l *lowerer) markInitial(e *element, children []*frontend.StateData, initial *element) {
+	// History states can never be the initial child.
+	var candidates []*frontend.StateData
+	for _, child := range children {
+		if child.History == "" {
+			candidates = append(candidates, child)
+		}
+	}
+	if len(candidates) == 0 {
+		if initial != nil || e.attr("initial") != "" {
+			l.errorf(e, "<%s> has an initial state but no children", e.name)
+		}
+		return
+	}
+
+	target := e.attr("initial")
+	if initial != nil {
+		if target != "" {
+			l.errorf(initial, "<initial> cannot be used along with the initial attribute of <%s>", e.name)
+			return
+		}
+		target = l.initialTarget(initial)
+	}
+
+	if target == "" {
+		candidates[0].Initial = true
+		return
+	}
+	if len(strings.Fields(target)) > 1 {
+		l.errorf(e, "several initial states (%q) are not supported", target)
+		return
+	}
+
+	for _, child := range candidates {
+		if child.Name == target {
+			child.Initial = true
+			return
+		}
+	}
+	l.errorf(e, "initial state %q is not a child of <%s>", target, e.name)
+}
+
+// initialTarget returns the target of the only transition an <initial> element is allowed to have.
+func (l *lowerer) initialTarget(initial *element) string {
+	var transitions []*element
+	for _, child := range initial.children {
+		if child.foreign {
+			continue
+		}
+		if child.name != "transition" {
+			l.unsupported(child, initial)
+			continue
+		}
+		transitions = append(transitions, child)
+	}
+
+	if len(transitions) != 1 {
+		l.errorf(initial, "<initial> requires exactly one <transition>, got %d", len(transitions))
+		return ""
+	}
+
+	transition := transitions[0]
+	if len(transition.children) > 0 {
+		l.errorf(transition, "executable content within the <transition> of <initial> is not supported")
+	}
+	target := transition.attr("target")
+	if target == "" {
+		l.errorf(transition, "the <transition> of <initial> requires a target")
+	}
+	return target
+}
+
+func (l *lowerer) lowerTransition(e *element, from string) {
+	tdata := &frontend.TransitionData{
+		From:  from,
+		To:    e.attr("target"),
+		Guard: e.attr("cond"),
+		Index: len(l.scdata.Transitions),
+		Pos:   e.pos,
+	}
+
+	// SCXML has no callbacks, so the actions are kept in a gochart specific attribute.
+	if actions := e.attr("gochart:actions"); actions != "" {
+		tdata.Actions = strings.Fields(actions)
+	}
+
+	events := strings.Fields(e.attr("event"))
+	switch {
+	case len(events) > 1:
+		l.errorf(e, "transitions on several events (%q) are not supported", e.attr("event"))
+	case len(events) == 1:
+		if strings.Contains(events[0], "*") {
+			l.errorf(e, "wildcard event descriptors (%q) are not supported", events[0])
+		}
+		tdata.Trigger = events[0]
+		l.addTrigger(e, events[0])
+	}
+
+	switch targets := strings.Fields(tdata.To); {
+	case len(targets) == 0:
+		l.errorf(e, "transitions without target are not supported")
+	case len(targets) > 1:
+		l.errorf(e, "transitions with several targets (%q) are not supported", tdata.To)
+	}
+
+	switch kind := e.attr("type"); kind {
+	case "", "external":
+	case "internal":
+		tdata.Internal = true
+	default:
+		l.errorf(e, "unknown transition type %q", kind)
+	}
+
+	for _, child := range e.children {
+		if !child.foreign {
+			l.errorf(child, "executable content within <transition> (<%s>) is not supported", child.name)
+		}
+	}
+
+	l.scdata.Transitions = append(l.scdata.Transitions, tdata)
+}
+
+// lowerReactions reads an <onentry> or <onexit> element. Each <raise> becomes a reaction to the
+// raised trigger, while an empty element marks the default reaction.
+func (l *lowerer) lowerReactions(e *element, defaultReaction *bool) []string {
+	var triggers []string
+	empty := true
+	for _, child := range e.children {
+		if child.foreign {
+			continue
+		}
+		empty = false
+
+		if child.name != "raise" {
+			l.errorf(child, "<%s> within <%s> is not supported, only <raise> is", child.name, e.name)
+			continue
+		}
+
+		event := child.attr("event")
+		if event == "" {
+			l.errorf(child, "<raise> requires an event attribute")
+			continue
+		}
+		l.addTrigger(child, event)
+		triggers = append(triggers, event)
+	}
+
+	if empty {
+		*defaultReaction = true
+	}
+	return triggers
+}
+
+// addTrigger declares |name| as a trigger the first time it is used. SCXML does not declare the
+// events up front, so they have no arguments.
+func (l *lowerer) addTrigger(e *element, name string) {
+	if l.triggers[name] {
+		return
+	}
+	l.triggers[name] = true
+
+	l.scdata.Triggers = append(l.scdata.Triggers, &frontend.TriggerData{
+		Name:  name,
+		Index: len(l.scdata.Triggers),
+		Pos:   e.pos,
+	})
+}
@@ -0,0 +1,41 @@
+// Package scxml is the frontend for W3C SCXML documents (https://www.w3.org/TR/scxml/). Only the
+// structural subset that maps to gochart concepts is supported: states, parallel states, final and
+// history states, transitions and enter/exit reactions. Data models, scripting and any executable
+// content besides <raise> within <onentry>/<onexit> are rejected. Transition actions can be given
+// through the gochart:actions attribute, as a space separated list.
+package scxml
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/cristiandonosoc/gochart/pkg/frontend"
+)
+
+var _ frontend.GochartFrontend = (*scxmlFrontend)(nil)
+
+type scxmlFrontend struct {
+}
+
+func NewScxmlFrontend() *scxmlFrontend {
+	return &scxmlFrontend{}
+}
+
+func (sf *scxmlFrontend) Process(r io.Reader) (*frontend.StatechartData, error) {
+	root, err := readDocument(r)
+	if err != nil {
+		return nil, fmt.Errorf("reading scxml input: %w", err)
+	}
+
+	scdata, errs := lowerDocument(root)
+	if errs != nil {
+		return nil, fmt.Errorf("processing scxml input: %w", errors.Join(errs...))
+	}
+
+	return scdata, nil
+}
+
+func (sf *scxmlFrontend) ProcessFromFile(path string) (*frontend.StatechartData, error) {
+	return frontend.ProcessFromFile(sf, path)
+}
@@ -0,0 +1,167 @@
+package scxml
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/cristiandonosoc/gochart/pkg/frontend"
+	"github.com/cristiandonosoc/gochart/pkg/ir"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProcessPlayer(t *testing.T) {
+	pos := func(line, column int) frontend.Position {
+		return frontend.Position{File: "testdata/player.scxml", Line: line, Column: column}
+	}
+
+	want := &frontend.StatechartData{
+		Name: "Player",
+		Triggers: []*frontend.TriggerData{
+			{Name: "Move", Index: 0, Pos: pos(7, 9)},
+			{Name: "Stop", Index: 1, Pos: pos(14, 9)},
+			{Name: "Reload", Index: 2, Pos: pos(23, 9)},
+			{Name: "Fire", Index: 3, Pos: pos(26, 9)},
+			{Name: "Die", Index: 4, Pos: pos(30, 5)},
+		},
+		States: []*frontend.StateData{
+			{Name: "Alive", Initial: true, Parallel: true, DefaultEnter: true, Index: 0, Pos: pos(3, 3)},
+			{Name: "Movement", Parent: "Alive", Index: 1, Pos: pos(5, 5)},
+			{Name: "Idle", Parent: "Movement", Initial: true, Index: 2, Pos: pos(6, 7)},
+			{Name: "Walking", Parent: "Movement", EnterReactionTriggers: []string{"Move"}, DefaultExit: true,
+				Index: 3, Pos: pos(9, 7)},
+			{Name: "Weapon", Parent: "Alive", Index: 4, Pos: pos(18, 5)},
+			{Name: "Firing", Parent: "Weapon", Index: 5, Pos: pos(22, 7)},
+			{Name: "Ready", Parent: "Weapon", Initial: true, Index: 6, Pos: pos(25, 7)},
+			{Name: "WeaponHistory", Parent: "Weapon", History: "deep", Index: 7, Pos: pos(28, 7)},
+			{Name: "Dead", Final: true, DefaultEnter: true, Index: 8, Pos: pos(32, 3)},
+		},
+		Transitions: []*frontend.TransitionData{
+			{From: "Idle", To: "Walking", Trigger: "Move", Guard: "CanMove", Index: 0, Pos: pos(7, 9)},
+			{From: "Walking", To: "Idle", Trigger: "Stop", Index: 1, Pos: pos(14, 9)},
+			{From: "Walking", To: "Walking", Trigger: "Move", Internal: true, Index: 2, Pos: pos(15, 9)},
+			{From: "Firing", To: "Ready", Trigger: "Reload", Index: 3, Pos: pos(23, 9)},
+			{From: "Ready", To: "Firing", Trigger: "Fire", Index: 4, Pos: pos(26, 9)},
+			{From: "Alive", To: "Dead", Trigger: "Die", Index: 5, Pos: pos(30, 5)},
+		},
+		Pos: pos(2, 1),
+	}
+
+	sf := NewScxmlFrontend()
+	got, err := sf.ProcessFromFile("testdata/player.scxml")
+	require.NoError(t, err)
+	assert.Equal(t, want, got)
+
+	// The result is a valid statechart.
+	_, err = ir.ProcessStatechartData(got)
+	require.NoError(t, err)
+}
+
+func TestProcessIgnoresForeignElements(t *testing.T) {
+	input := `
+<scxml xmlns="http://www.w3.org/2005/07/scxml" xmlns:qt="http://www.qt.io/2015/02/scxml-ext" name="Door">
+  <qt:editorinfo initialGeometry="0;0;0;0"/>
+  <state id="Closed">
+    <qt:editorinfo geometry="1;2;3;4"/>
+    <transition event="Open" target="Opened"/>
+  </state>
+  <state id="Opened"/>
+</scxml>`
+
+	sf := NewScxmlFrontend()
+	got, err := sf.Process(strings.NewReader(input))
+	require.NoError(t, err)
+
+	require.Len(t, got.States, 2)
+	assert.True(t, got.States[0].Initial)
+	assert.False(t, got.States[1].Initial)
+}
+
+func TestProcessErrors(t *testing.T) {
+	testcases := []struct {
+		name     string
+		input    string
+		wantErrs []string
+	}{
+		{
+			name:     "not scxml",
+			input:    `<statechart name="Foo"/>`,
+			wantErrs: []string{"line 1, char 1: expected <scxml> as the root element, got <statechart>"},
+		},
+		{
+			name:  "missing names",
+			input: `<scxml><state><transition event="Go" target="B"/></state></scxml>`,
+			wantErrs: []string{
+				"line 1, char 1: <scxml> requires a name attribute",
+				"line 1, char 8: <state> requires an id attribute",
+			},
+		},
+		{
+			name: "data model and scripting",
+			input: `<scxml name="Foo">
+  <datamodel><data id="x" expr="1"/></datamodel>
+  <state id="A">
+    <onentry><assign location="x" expr="2"/></onentry>
+    <invoke type="http://www.w3.org/TR/scxml/" src="other.scxml"/>
+    <transition event="Go" target="A"><log expr="x"/></transition>
+  </state>
+</scxml>`,
+			wantErrs: []string{
+				"line 2, char 3: <datamodel> within <scxml> is not supported",
+				"line 4, char 14: <assign> within <onentry> is not supported, only <raise> is",
+				"line 5, char 5: <invoke> within <state> is not supported",
+				"line 6, char 39: executable content within <transition> (<log>) is not supported",
+			},
+		},
+		{
+			name: "unsupported transitions",
+			input: `<scxml name="Foo">
+  <state id="A">
+    <transition event="Go Stop" target="B"/>
+    <transition event="error.*" target="B"/>
+    <transition event="Go" target="A B"/>
+    <transition event="Go"/>
+  </state>
+  <state id="B"/>
+</scxml>`,
+			wantErrs: []string{
+				`line 3, char 5: transitions on several events ("Go Stop") are not supported`,
+				`line 4, char 5: wildcard event descriptors ("error.*") are not supported`,
+				`line 5, char 5: transitions with several targets ("A B") are not supported`,
+				`line 6, char 5: transitions without target are not supported`,
+			},
+		},
+		{
+			name: "initial states",
+			input: `<scxml name="Foo" initial="A B">
+  <state id="A" initial="Missing">
+    <state id="A1"/>
+  </state>
+  <state id="B">
+    <initial><transition target="B1"/></initial>
+    <state id="B1"/>
+    <history id="BHistory"><transition target="B1"/></history>
+  </state>
+</scxml>`,
+			wantErrs: []string{
+				`line 2, char 3: initial state "Missing" is not a child of <state>`,
+				"line 8, char 28: default transitions of <history> are not supported",
+				`line 1, char 1: several initial states ("A B") are not supported`,
+			},
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			sf := NewScxmlFrontend()
+			_, err := sf.Process(strings.NewReader(tc.input))
+			require.Error(t, err)
+
+			for _, wantErr := range tc.wantErrs {
+				assert.Contains(t, err.Error(), wantErr)
+			}
+			assert.Equal(t, len(tc.wantErrs), strings.Count(err.Error(), "line "))
+		})
+	}
+}
@@ -0,0 +1,170 @@
+package xstate
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/cristiandonosoc/gochart/pkg/frontend"
+)
+
+// encoding/json loses the order of the object keys, which XState uses for the states, so the
+// document is read token by token into a tree that keeps it, along with the positions.
+
+type nodeKind int
+
+const (
+	node_Object nodeKind = iota
+	node_Array
+	node_String
+	node_Bool
+	node_Other
+)
+
+func (nk nodeKind) String() string {
+	switch nk {
+	case node_Object:
+		return "object"
+	case node_Array:
+		return "array"
+	case node_String:
+		return "string"
+	case node_Bool:
+		return "boolean"
+	case node_Other:
+		return "value"
+	}
+
+	return fmt.Sprintf("<invalid node kind %d>", int(nk))
+}
+
+// node is a JSON value.
+type node struct {
+	kind nodeKind
+
+	fields []*field // For objects, in document order.
+	items  []*node  // For arrays.
+	str    string   // For strings.
+	b      bool     // For booleans.
+
+	pos frontend.Position
+}
+
+// field is a member of a JSON object.
+type field struct {
+	key   string
+	pos   frontend.Position
+	value *node
+}
+
+// get returns the value of |key| within an object. nil if not found.
+func (n *node) get(key string) *node {
+	for _, f := range n.fields {
+		if f.key == key {
+			return f.value
+		}
+	}
+	return nil
+}
+
+type documentReader struct {
+	data       []byte
+	decoder    *json.Decoder
+	lineStarts []int
+}
+
+// readDocument reads the whole JSON document and returns its root value.
+func readDocument(r io.Reader) (*node, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("reading input reader: %w", err)
+	}
+
+	dr := &documentReader{
+		data:       data,
+		decoder:    json.NewDecoder(bytes.NewReader(data)),
+		lineStarts: []int{0},
+	}
+	for i, c := range data {
+		if c == '\n' {
+			dr.lineStarts = append(dr.lineStarts, i+1)
+		}
+	}
+
+	root, err := dr.readValue()
+	if err != nil {
+		return nil, fmt.Errorf("parsing json: %w", err)
+	}
+	return root, nil
+}
+
+// nextPos returns where the next token starts.
+func (dr *documentReader) nextPos() frontend.Position {
+	offset := int(dr.decoder.InputOffset())
+	for offset < len(dr.data) {
+		switch dr.data[offset] {
+		case ' ', '\t', '\r', '\n', ',', ':':
+			offset++
+			continue
+		}
+		break
+	}
+
+	line := sort.Search(len(dr.lineStarts), func(i int) bool { return dr.lineStarts[i] > offset })
+	return frontend.Position{Line: line, Column: offset - dr.lineStarts[line-1] + 1}
+}
+
+func (dr *documentReader) readValue() (*node, error) {
+	n := &node{pos: dr.nextPos()}
+
+	token, err := dr.decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	switch token := token.(type) {
+	case json.Delim:
+		if token == '{' {
+			n.kind = node_Object
+			for dr.decoder.More() {
+				f := &field{pos: dr.nextPos()}
+				key, err := dr.decoder.Token()
+				if err != nil {
+					return nil, err
+				}
+				f.key = key.(string)
+
+				if f.value, err = dr.readValue(); err != nil {
+					return nil, err
+				}
+				n.fields = append(n.fields, f)
+			}
+		} else {
+			n.kind = node_Array
+			for dr.decoder.More() {
+				item, err := dr.readValue()
+				if err != nil {
+					return nil, err
+				}
+				n.items = append(n.items, item)
+			}
+		}
+
+		// Consume the closing delimiter.
+		if _, err := dr.decoder.Token(); err != nil {
+			return nil, err
+		}
+	case string:
+		n.kind = node_String
+		n.str = token
+	case bool:
+		n.kind = node_Bool
+		n.b = token
+	default:
+		n.kind = node_Other
+	}
+
+	return n, nil
+}
@@ -0,0 +1,413 @@
+package xstate
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/cristiandonosoc/gochart/pkg/frontend"
+)
+
+// LOWERING ----------------------------------------------------------------------------------------
+
+// lowerer converts the XState configuration into the frontend representation. It collects all the
+// problems instead of stopping at the first one, so that the user can fix the document in one go.
+type lowerer struct {
+	scdata   *frontend.StatechartData
+	triggers map[string]bool
+	errs     []error
+
+	// reenters holds whether each transition explicitly re-enters its source. Transitions without it
+	// are resolved once all the states are known.
+	reenters map[*frontend.TransitionData]*bool
+
+	// states holds the lowered states by name. XState keys only need to be unique among siblings,
+	// but gochart names the states globally.
+	states map[string]*frontend.StateData
+}
+
+func (l *lowerer) errorf(pos frontend.Position, format string, args ...any) {
+	msg := fmt.Sprintf(format, args...)
+	l.errs = append(l.errs, fmt.Errorf("line %d, char %d: %s", pos.Line, pos.Column, msg))
+}
+
+// checkProperties reports the properties of |n| that are not among |supported|.
+func (l *lowerer) checkProperties(n *node, where string, supported ...string) {
+	for _, f := range n.fields {
+		found := false
+		for _, property := range supported {
+			if f.key == property {
+				found = true
+				break
+			}
+		}
+		if !found {
+			l.errorf(f.pos, "property %q of %s is not supported", f.key, where)
+		}
+	}
+}
+
+// expect reports when |n| is not of |kind|.
+func (l *lowerer) expect(n *node, kind nodeKind, what string) bool {
+	if n.kind != kind {
+		l.errorf(n.pos, "%s must be a %s, got a %s", what, kind, n.kind)
+		return false
+	}
+	return true
+}
+
+// stringProperty returns the string value of |key| within |n|. Empty if it is not present.
+func (l *lowerer) stringProperty(n *node, key string, what string) string {
+	value := n.get(key)
+	if value == nil || !l.expect(value, node_String, what) {
+		return ""
+	}
+	return value.str
+}
+
+func lowerMachine(root *node) (*frontend.StatechartData, []error) {
+	l := &lowerer{
+		scdata:   &frontend.StatechartData{Pos: root.pos},
+		triggers: make(map[string]bool),
+		reenters: make(map[*frontend.TransitionData]*bool),
+		states:   make(map[string]*frontend.StateData),
+	}
+
+	if !l.expect(root, node_Object, "the machine configuration") {
+		return nil, l.errs
+	}
+	l.checkProperties(root, "the machine", "id", "initial", "states", "meta", "description", "version")
+
+	l.scdata.Name = l.stringProperty(root, "id", `the "id" of the machine`)
+	if l.scdata.Name == "" {
+		l.errorf(root.pos, `the machine requires an "id", as it is the name of the statechart`)
+	}
+
+	if meta := root.get("meta"); meta != nil {
+		l.declareTriggers(meta)
+	}
+
+	var children []*frontend.StateData
+	if states := root.get("states"); states != nil {
+		children = l.lowerStates(states, "")
+	}
+	l.markInitial(root, "the machine", children)
+
+	l.resolveInternalTransitions()
+
+	if l.errs != nil {
+		return nil, l.errs
+	}
+
+	return l.scdata, nil
+}
+
+// declareTriggers reads the "gochart.triggers" property of the machine meta, which holds the
+// triggers along with their arguments.
+func (l *lowerer) declareTriggers(meta *node) {
+	if !l.expect(meta, node_Object, `the "meta" of the machine`) {
+		return
+	}
+	gochart := meta.get("gochart")
+	if gochart == nil || !l.expect(gochart, node_Object, `"meta.gochart"`) {
+		return
+	}
+	triggers := gochart.get("triggers")
+	if triggers == nil || !l.expect(triggers, node_Array, `"meta.gochart.triggers"`) {
+		return
+	}
+
+	for _, trigger := range triggers.items {
+		if !l.expect(trigger, node_Object, "a trigger declaration") {
+			continue
+		}
+		l.checkProperties(trigger, "a trigger declaration", "name", "arguments")
+
+		name := l.stringProperty(trigger, "name", "the name of a trigger")
+		if name == "" {
+			l.errorf(trigger.pos, "trigger declarations require a name")
+			continue
+		}
+		l.addTrigger(name, trigger.pos)
+		l.scdata.Triggers[len(l.scdata.Triggers)-1].ArgumentsString =
+			l.stringProperty(trigger, "arguments", "the arguments of a trigger")
+	}
+}
+
+// addTrigger declares |name| as a trigger the first time it is found.
+func (l *lowerer) addTrigger(name string, pos frontend.Position) {
+	if l.triggers[name] {
+		return
+	}
+	l.triggers[name] = true
+
+	l.scdata.Triggers = append(l.scdata.Triggers, &frontend.TriggerData{
+		Name:  name,
+		Index: len(l.scdata.Triggers),
+		Pos:   pos,
+	})
+}
+
+func (l *lowerer) lowerStates(states *node, parent string) []*frontend.StateData {
+	if !l.expect(states, node_Object, `"states"`) {
+		return nil
+	}
+
+	var children []*frontend.StateData
+	for _, f := range states.fields {
+		if sdata := l.lowerState(f, parent); sdata != nil {
+			children = append(children, sdata)
+		}
+	}
+	return children
+}
+
+func (l *lowerer) lowerState(f *field, parent string) *frontend.StateData {
+	n := f.value
+	where := fmt.Sprintf("state %q", f.key)
+	if !l.expect(n, node_Object, where) {
+		return nil
+	}
+	l.checkProperties(n, where, "id", "type", "initial", "states", "on", "always", "entry", "exit",
+		"history", "description", "meta", "tags")
+
+	sdata := &frontend.StateData{
+		Name:   f.key,
+		Parent: parent,
+		Index:  len(l.scdata.States),
+		Pos:    f.pos,
+	}
+
+	// The duplicate is still returned, so that the "initial" of its parent does not report it as
+	// missing, but nothing within it is lowered.
+	if previous, ok := l.states[f.key]; ok {
+		l.errorf(f.pos, "state %q is already declared at %s, and state names must be unique", f.key, previous.Pos)
+		return sdata
+	}
+	l.states[f.key] = sdata
+	l.scdata.States = append(l.scdata.States, sdata)
+
+	switch kind := l.stringProperty(n, "type", fmt.Sprintf(`the "type" of %s`, where)); kind {
+	case "", "atomic", "compound":
+	case "parallel":
+		sdata.Parallel = true
+	case "final":
+		sdata.Final = true
+	case "history":
+		// XState histories are shallow unless specified.
+		sdata.History = "shallow"
+		if history := l.stringProperty(n, "history", fmt.Sprintf(`the "history" of %s`, where)); history != "" {
+			sdata.History = history
+		}
+	default:
+		l.errorf(n.get("type").pos, "unknown state type %q", kind)
+	}
+
+	if entry := n.get("entry"); entry != nil {
+		sdata.EnterReactionTriggers = l.lowerReactions(entry, sdata.Name, "OnEnter", &sdata.DefaultEnter)
+	}
+	if exit := n.get("exit"); exit != nil {
+		sdata.ExitReactionTriggers = l.lowerReactions(exit, sdata.Name, "OnExit", &sdata.DefaultExit)
+	}
+
+	if on := n.get("on"); on != nil && l.expect(on, node_Object, fmt.Sprintf(`the "on" of %s`, where)) {
+		for _, event := range on.fields {
+			if strings.Contains(event.key, "*") {
+				l.errorf(event.pos, "wildcard event descriptors (%q) are not supported", event.key)
+				continue
+			}
+			l.addTrigger(event.key, event.pos)
+			l.lowerTransitions(event.value, sdata.Name, event.key, event.pos)
+		}
+	}
+	if always := n.get("always"); always != nil {
+		l.lowerTransitions(always, sdata.Name, "", always.pos)
+	}
+
+	var children []*frontend.StateData
+	if states := n.get("states"); states != nil {
+		children = l.lowerStates(states, sdata.Name)
+	}
+
+	// Regions of a parallel state are all entered, so there is no initial one to mark.
+	if !sdata.Parallel {
+		l.markInitial(n, where, children)
+	}
+
+	return sdata
+}
+
+// markInitial marks the child named by the "initial" property of |n|.
+func (l *lowerer) markInitial(n *node, where string, children []*frontend.StateData) {
+	initial := l.stringProperty(n, "initial", fmt.Sprintf(`the "initial" of %s`, where))
+	if initial == "" {
+		return
+	}
+
+	for _, child := range children {
+		if child.Name == initial && child.History == "" {
+			child.Initial = true
+			return
+		}
+	}
+	l.errorf(n.get("initial").pos, "initial state %q is not a child of %s", initial, where)
+}
+
+// lowerTransitions reads the transitions of one event, which can be given as a target, a
+// transition object or an array of both.
+func (l *lowerer) lowerTransitions(n *node, from string, event string, pos frontend.Position) {
+	if n.kind != node_Array {
+		l.lowerTransition(n, from, event, pos)
+		return
+	}
+
+	for _, item := range n.items {
+		l.lowerTransition(item, from, event, item.pos)
+	}
+}
+
+func (l *lowerer) lowerTransition(n *node, from string, event string, pos frontend.Position) {
+	tdata := &frontend.TransitionData{
+		From:    from,
+		Trigger: event,
+		Index:   len(l.scdata.Transitions),
+		Pos:     pos,
+	}
+
+	var target *node
+	switch n.kind {
+	case node_String:
+		target = n
+	case node_Object:
+		l.checkProperties(n, "a transition", "target", "guard", "cond", "actions", "reenter", "internal",
+			"description", "meta")
+		target = n.get("target")
+
+		for _, key := range []string{"guard", "cond"} {
+			if guard := n.get(key); guard != nil {
+				tdata.Guard = l.implementationName(guard, "a guard")
+			}
+		}
+
+		if actions := n.get("actions"); actions != nil {
+			tdata.Actions = l.implementationNames(actions, "an action")
+		}
+
+		if reenter := n.get("reenter"); reenter != nil && l.expect(reenter, node_Bool, `"reenter"`) {
+			l.reenters[tdata] = &reenter.b
+		}
+		// XState v4 used "internal" instead, with the opposite meaning.
+		if internal := n.get("internal"); internal != nil && l.expect(internal, node_Bool, `"internal"`) {
+			reenter := !internal.b
+			l.reenters[tdata] = &reenter
+		}
+	default:
+		l.errorf(n.pos, "a transition must be a target or an object, got a %s", n.kind)
+		return
+	}
+
+	// Targets can also be given as arrays, although gochart only supports one.
+	if target != nil && target.kind == node_Array {
+		if len(target.items) != 1 {
+			l.errorf(target.pos, "transitions with several targets are not supported")
+			return
+		}
+		target = target.items[0]
+	}
+	if target == nil {
+		l.errorf(n.pos, "transitions without target are not supported")
+		return
+	}
+	if !l.expect(target, node_String, "a transition target") {
+		return
+	}
+	tdata.To = resolveTarget(target.str)
+
+	l.scdata.Transitions = append(l.scdata.Transitions, tdata)
+}
+
+// resolveTarget returns the name of the state a target points to. XState targets can be sibling
+// keys ("B"), child keys (".B"), paths ("A.B") or ids ("#B"). As gochart state names are unique, the
+// last key of the path is enough.
+func resolveTarget(target string) string {
+	target = strings.TrimPrefix(target, "#")
+	if i := strings.LastIndex(target, "."); i >= 0 {
+		target = target[i+1:]
+	}
+	return target
+}
+
+// resolveInternalTransitions decides which transitions do not exit their source. As in XState v5,
+// unless "reenter" is set, transitions to the source itself or to its descendants are internal.
+func (l *lowerer) resolveInternalTransitions() {
+	parents := make(map[string]string)
+	for _, sdata := range l.scdata.States {
+		parents[sdata.Name] = sdata.Parent
+	}
+
+	for _, tdata := range l.scdata.Transitions {
+		if reenter, ok := l.reenters[tdata]; ok {
+			tdata.Internal = !*reenter
+			continue
+		}
+
+		// The visited states stop the walk should the parents ever loop.
+		visited := make(map[string]bool)
+		for current := tdata.To; current != "" && !visited[current]; current = parents[current] {
+			visited[current] = true
+			if current == tdata.From {
+				tdata.Internal = true
+				break
+			}
+		}
+	}
+}
+
+// lowerReactions reads the entry or exit actions of a state. Only the ones named after the gochart
+// callbacks of the state are supported (see the package documentation).
+func (l *lowerer) lowerReactions(n *node, state string, kind string, defaultReaction *bool) []string {
+	callback := fmt.Sprintf("State%s_%s", state, kind)
+
+	var triggers []string
+	for _, action := range l.implementationNames(n, "an action") {
+		switch {
+		case action == callback:
+			*defaultReaction = true
+		case strings.HasPrefix(action, callback+"_"):
+			trigger := strings.TrimPrefix(action, callback+"_")
+			l.addTrigger(trigger, n.pos)
+			triggers = append(triggers, trigger)
+		default:
+			l.errorf(n.pos, "action %q of state %q is not supported, only %q and %q are",
+				action, state, callback, callback+"_<Trigger>")
+		}
+	}
+	return triggers
+}
+
+// implementationNames reads a list of actions, which can be given as a single one or as an array.
+func (l *lowerer) implementationNames(n *node, what string) []string {
+	if n.kind != node_Array {
+		return []string{l.implementationName(n, what)}
+	}
+
+	var names []string
+	for _, item := range n.items {
+		names = append(names, l.implementationName(item, what))
+	}
+	return names
+}
+
+// implementationName reads the name of a guard or action, which can be given as a string or as an
+// object with a "type". Inline implementations cannot be written in JSON.
+func (l *lowerer) implementationName(n *node, what string) string {
+	switch n.kind {
+	case node_String:
+		return n.str
+	case node_Object:
+		l.checkProperties(n, what, "type")
+		return l.stringProperty(n, "type", fmt.Sprintf(`the "type" of %s`, what))
+	}
+
+	l.errorf(n.pos, "%s must be a name or an object, got a %s", what, n.kind)
+	return ""
+}
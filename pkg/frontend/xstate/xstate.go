@@ -0,0 +1,47 @@
+// Package xstate is the frontend for XState (v5) machine configurations written as JSON
+// (https://stately.ai/docs/machines). Only the structural subset that maps to gochart concepts is
+// supported: nested, parallel, final and history states, event and eventless ("always")
+// transitions, guards and actions given by name, and entry/exit actions.
+//
+// XState has no enter/exit reactions, but entry and exit actions named after the gochart callbacks
+// are mapped to them: "State<Name>_OnEnter" is the default enter reaction of state <Name>, and
+// "State<Name>_OnEnter_<Trigger>" the one specific to <Trigger> (same for "OnExit").
+//
+// XState does not declare events either. They can be declared, with their arguments, in the
+// "meta.gochart.triggers" property of the machine. Otherwise they are declared in order of use.
+package xstate
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/cristiandonosoc/gochart/pkg/frontend"
+)
+
+var _ frontend.GochartFrontend = (*xstateFrontend)(nil)
+
+type xstateFrontend struct {
+}
+
+func NewXStateFrontend() *xstateFrontend {
+	return &xstateFrontend{}
+}
+
+func (xf *xstateFrontend) Process(r io.Reader) (*frontend.StatechartData, error) {
+	root, err := readDocument(r)
+	if err != nil {
+		return nil, fmt.Errorf("reading xstate input: %w", err)
+	}
+
+	scdata, errs := lowerMachine(root)
+	if errs != nil {
+		return nil, fmt.Errorf("processing xstate input: %w", errors.Join(errs...))
+	}
+
+	return scdata, nil
+}
+
+func (xf *xstateFrontend) ProcessFromFile(path string) (*frontend.StatechartData, error) {
+	return frontend.ProcessFromFile(xf, path)
+}
@@ -0,0 +1,162 @@
+package xstate
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/cristiandonosoc/gochart/pkg/frontend"
+	"github.com/cristiandonosoc/gochart/pkg/ir"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProcessDoor(t *testing.T) {
+	pos := func(line, column int) frontend.Position {
+		return frontend.Position{File: "testdata/door.xstate.json", Line: line, Column: column}
+	}
+
+	want := &frontend.StatechartData{
+		Name: "Door",
+		Triggers: []*frontend.TriggerData{
+			{Name: "Open", ArgumentsString: "int force", Index: 0, Pos: pos(7, 9)},
+			{Name: "Lock", Index: 1, Pos: pos(20, 19)},
+			{Name: "Unlock", Index: 2, Pos: pos(23, 19)},
+			{Name: "Close", Index: 3, Pos: pos(35, 15)},
+		},
+		States: []*frontend.StateData{
+			{Name: "Closed", Initial: true, DefaultEnter: true, Index: 0, Pos: pos(12, 5)},
+			{Name: "Unlocked", Parent: "Closed", Initial: true, Index: 1, Pos: pos(19, 9)},
+			{Name: "Locked", Parent: "Closed", DefaultExit: true, ExitReactionTriggers: []string{"Unlock"},
+				Index: 2, Pos: pos(22, 9)},
+			{Name: "hist", Parent: "Closed", History: "deep", Index: 3, Pos: pos(31, 9)},
+			{Name: "Opened", Index: 4, Pos: pos(34, 5)},
+			{Name: "Broken", Final: true, Index: 5, Pos: pos(38, 5)},
+		},
+		Transitions: []*frontend.TransitionData{
+			{From: "Closed", To: "Opened", Trigger: "Open", Guard: "IsUnlocked", Actions: []string{"Creak"},
+				Index: 0, Pos: pos(16, 9)},
+			{From: "Unlocked", To: "Locked", Trigger: "Lock", Index: 1, Pos: pos(20, 19)},
+			{From: "Locked", To: "Unlocked", Trigger: "Unlock", Guard: "HasKey", Index: 2, Pos: pos(26, 15)},
+			// Targets within the source do not exit it unless "reenter" is set.
+			{From: "Locked", To: "Locked", Trigger: "Unlock", Internal: true, Index: 3, Pos: pos(27, 15)},
+			{From: "Opened", To: "hist", Trigger: "Close", Index: 4, Pos: pos(35, 15)},
+			{From: "Opened", To: "Broken", Guard: "IsBroken", Index: 5, Pos: pos(36, 18)},
+		},
+		Pos: pos(1, 1),
+	}
+
+	xf := NewXStateFrontend()
+	got, err := xf.ProcessFromFile("testdata/door.xstate.json")
+	require.NoError(t, err)
+	assert.Equal(t, want, got)
+
+	// The result is a valid statechart.
+	_, err = ir.ProcessStatechartData(got)
+	require.NoError(t, err)
+}
+
+func TestProcessReenter(t *testing.T) {
+	input := `{
+  "id": "Test",
+  "initial": "A",
+  "states": {
+    "A": {
+      "initial": "A1",
+      "on": {
+        "Reset": { "target": ".A1", "reenter": true },
+        "Restart": { "target": "A", "internal": false },
+        "Stay": { "target": "A", "internal": true }
+      },
+      "states": { "A1": {} }
+    }
+  }
+}`
+
+	xf := NewXStateFrontend()
+	got, err := xf.Process(strings.NewReader(input))
+	require.NoError(t, err)
+
+	require.Len(t, got.Transitions, 3)
+	assert.Equal(t, "A1", got.Transitions[0].To)
+	assert.False(t, got.Transitions[0].Internal)
+	assert.False(t, got.Transitions[1].Internal)
+	assert.True(t, got.Transitions[2].Internal)
+}
+
+func TestProcessErrors(t *testing.T) {
+	testcases := []struct {
+		name     string
+		input    string
+		wantErrs []string
+	}{
+		{
+			name:     "not an object",
+			input:    `["Foo"]`,
+			wantErrs: []string{"line 1, char 1: the machine configuration must be a object, got a array"},
+		},
+		{
+			name:  "unsupported properties",
+			input: `{"id": "Foo", "context": {}, "states": {"A": {"invoke": {"src": "fetch"}, "after": {"1000": "A"}}}}`,
+			wantErrs: []string{
+				`line 1, char 15: property "context" of the machine is not supported`,
+				`line 1, char 47: property "invoke" of state "A" is not supported`,
+				`line 1, char 75: property "after" of state "A" is not supported`,
+			},
+		},
+		{
+			name:  "duplicate state",
+			input: `{"id":"M","initial":"A","states":{"A":{"initial":"B","states":{"B":{"initial":"A","states":{"A":{}}}}},"C":{"on":{"Go":"A"}}}}`,
+			wantErrs: []string{
+				`line 1, char 93: state "A" is already declared at <input>:1:35, and state names must be unique`,
+			},
+		},
+		{
+			name: "unsupported transitions",
+			input: `{
+  "id": "Foo",
+  "states": {
+    "A": {
+      "on": {
+        "*": "A",
+        "Go": { "actions": "Log" },
+        "Stop": { "target": ["A", "B"] }
+      }
+    }
+  }
+}`,
+			wantErrs: []string{
+				`line 6, char 9: wildcard event descriptors ("*") are not supported`,
+				`line 7, char 15: transitions without target are not supported`,
+				`line 8, char 29: transitions with several targets are not supported`,
+			},
+		},
+		{
+			name: "unsupported actions",
+			input: `{
+  "id": "Foo",
+  "initial": "Missing",
+  "states": {
+    "A": { "entry": ["Log", "StateA_OnEnter"] }
+  }
+}`,
+			wantErrs: []string{
+				`line 5, char 21: action "Log" of state "A" is not supported, only "StateA_OnEnter" and "StateA_OnEnter_<Trigger>" are`,
+				`line 3, char 14: initial state "Missing" is not a child of the machine`,
+			},
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			xf := NewXStateFrontend()
+			_, err := xf.Process(strings.NewReader(tc.input))
+			require.Error(t, err)
+
+			for _, wantErr := range tc.wantErrs {
+				assert.Contains(t, err.Error(), wantErr)
+			}
+			assert.Equal(t, len(tc.wantErrs), strings.Count(err.Error(), "line "))
+		})
+	}
+}
@@ -1,14 +1,52 @@
 // Package yaml is a simple frontend that reads yaml. Mostly used to quickly test the whole pipeline
 // instead of requiring a custom language/parser.
+//
+// States can be written either as a flat list referencing their parent by name, or nested within
+// the "states" key of their parent. Likewise, transitions can be written in the top level
+// "transitions" list with their source in "from", or within the "transitions" key of their source.
+// Both forms can be mixed:
+//
+//	name: Door
+//	states:
+//	  - name: Closed
+//	    initial: true
+//	    states:
+//	      - name: Unlocked
+//	        initial: true
+//	        transitions:
+//	          - to: Locked
+//	            trigger: Lock
+//	      - name: Locked
+//	  - name: Opened
+//	transitions:
+//	  - from: Closed
+//	    to: Opened
+//	    trigger: Open
+//
+// Charts can be split across files with "include", which lists paths relative to the including
+// file. At the top level, the triggers, states and transitions of the included files are merged
+// before the ones of the chart. Within a state, the top level states of the included files are
+// nested within it. The name of included files is ignored, and can be omitted:
+//
+//	name: Player
+//	include: [triggers.yaml]
+//	states:
+//	  - name: Alive
+//	    initial: true
+//	    include: [movement.yaml]
+//
+// See frontend.Includer for how includes are resolved.
 package yaml
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
 	"io"
 
 	"github.com/cristiandonosoc/gochart/pkg/frontend"
 
-	"gopkg.in/yaml.v2"
+	"gopkg.in/yaml.v3"
 )
 
 func NewYamlFrontend() *yamlFrontend {
@@ -20,20 +58,232 @@ var _ frontend.GochartFrontend = (*yamlFrontend)(nil)
 type yamlFrontend struct {
 }
 
+// yamlStatechart is the shape of the yaml input, which extends the frontend data with the nested
+// forms.
+type yamlStatechart struct {
+	Name        string                     `yaml:"name"`
+	Include     []string                   `yaml:"include"`
+	Triggers    []*frontend.TriggerData    `yaml:"triggers"`
+	States      []*yamlState               `yaml:"states"`
+	Transitions []*frontend.TransitionData `yaml:"transitions"`
+}
+
+type yamlState struct {
+	frontend.StateData `yaml:",inline"`
+
+	Include     []string                   `yaml:"include"`
+	States      []*yamlState               `yaml:"states"`
+	Transitions []*frontend.TransitionData `yaml:"transitions"`
+}
+
+// Process reads a chart from |r|. Its includes are relative to the working directory.
 func (yf *yamlFrontend) Process(r io.Reader) (*frontend.StatechartData, error) {
+	scdata, errs := process(r, frontend.NewIncluder())
+	if errs != nil {
+		return nil, fmt.Errorf("processing yaml: %w", errors.Join(errs...))
+	}
+
+	return scdata, nil
+}
+
+func (yf *yamlFrontend) ProcessFromFile(path string) (*frontend.StatechartData, error) {
+	includer := frontend.NewIncluder()
+	scdata, err := includer.Include(path, frontend.Position{}, func(r io.Reader) (*frontend.StatechartData, error) {
+		scdata, errs := process(r, includer)
+		return scdata, errors.Join(errs...)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("processing yaml: %w", err)
+	}
+
+	return scdata, nil
+}
+
+// process reads a single yaml file, with |includer| resolving the files it includes.
+func process(r io.Reader, includer *frontend.Includer) (*frontend.StatechartData, []error) {
 	data, err := io.ReadAll(r)
 	if err != nil {
-		return nil, fmt.Errorf("reading input reader: %w", err)
+		return nil, []error{fmt.Errorf("reading input reader: %w", err)}
+	}
+
+	// Unknown keys are most likely typos (eg. "intial"), which would otherwise be silently ignored.
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+
+	var input yamlStatechart
+	if err := decoder.Decode(&input); err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, []error{fmt.Errorf("unmarshalling yaml: empty input")}
+		}
+		return nil, []error{fmt.Errorf("unmarshalling yaml: %w", err)}
 	}
 
-	var scdata frontend.StatechartData
-	if err := yaml.Unmarshal(data, &scdata); err != nil {
-		return nil, fmt.Errorf("unmarshalling yaml: %w", err)
+	// The decoding into the structs loses where each element was defined, so we read the document
+	// again as nodes to recover it.
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return nil, []error{fmt.Errorf("unmarshalling yaml nodes: %w", err)}
 	}
 
-	return &scdata, nil
+	return lower(&input, &root, includer)
 }
 
-func (yf *yamlFrontend) ProcessFromFile(path string) (*frontend.StatechartData, error) {
-	return frontend.ProcessFromFile(yf, path)
+// LOWERING ----------------------------------------------------------------------------------------
+
+// lowerer flattens the nested states and transitions into the frontend representation, and fills
+// the positions and indices of the elements from the document nodes they were decoded from.
+// Elements are positioned at the start of their definition, and the statechart at its name.
+type lowerer struct {
+	scdata   *frontend.StatechartData
+	includer *frontend.Includer
+	errs     []error
+}
+
+func (l *lowerer) errorf(pos frontend.Position, format string, args ...any) {
+	msg := fmt.Sprintf(format, args...)
+	l.errs = append(l.errs, fmt.Errorf("line %d, char %d: %s", pos.Line, pos.Column, msg))
+}
+
+func lower(input *yamlStatechart, root *yaml.Node, includer *frontend.Includer) (*frontend.StatechartData, []error) {
+	l := &lowerer{
+		scdata: &frontend.StatechartData{
+			Name: input.Name,
+		},
+		includer: includer,
+	}
+
+	var statechart *yaml.Node
+	if root.Kind == yaml.DocumentNode && len(root.Content) > 0 {
+		statechart = root.Content[0]
+	}
+	if name := mappingValue(statechart, "name"); name != nil {
+		l.scdata.Pos = nodePosition(name)
+	}
+
+	l.include(input.Include, "", mappingValue(statechart, "include"))
+
+	triggers := sequenceItems(mappingValue(statechart, "triggers"))
+	for i, tdata := range input.Triggers {
+		tdata.Index = len(l.scdata.Triggers)
+		tdata.Pos = itemPosition(triggers, i)
+		l.scdata.Triggers = append(l.scdata.Triggers, tdata)
+	}
+
+	states := sequenceItems(mappingValue(statechart, "states"))
+	for i, state := range input.States {
+		l.lowerState(state, "", itemNode(states, i))
+	}
+
+	l.lowerTransitions(input.Transitions, "", mappingValue(statechart, "transitions"))
+
+	if l.errs != nil {
+		return nil, l.errs
+	}
+
+	return l.scdata, nil
+}
+
+// lowerState adds |state| and everything nested within it. |parent| is the state it is nested
+// within, if any.
+func (l *lowerer) lowerState(state *yamlState, parent string, node *yaml.Node) {
+	sdata := &state.StateData
+	sdata.Index = len(l.scdata.States)
+	sdata.Pos = nodePosition(node)
+
+	if parent != "" {
+		if sdata.Parent != "" && sdata.Parent != parent {
+			l.errorf(sdata.Pos, "state %q is nested within %q but has %q as parent", sdata.Name, parent,
+				sdata.Parent)
+		}
+		sdata.Parent = parent
+	}
+	l.scdata.States = append(l.scdata.States, sdata)
+
+	l.lowerTransitions(state.Transitions, sdata.Name, mappingValue(node, "transitions"))
+
+	children := sequenceItems(mappingValue(node, "states"))
+	for i, child := range state.States {
+		l.lowerState(child, sdata.Name, itemNode(children, i))
+	}
+
+	l.include(state.Include, sdata.Name, mappingValue(node, "include"))
+}
+
+// include merges the files in |paths|, with their top level states nested within |parent|.
+func (l *lowerer) include(paths []string, parent string, node *yaml.Node) {
+	items := sequenceItems(node)
+	for i, path := range paths {
+		pos := itemPosition(items, i)
+		included, err := l.includer.Include(path, pos, func(r io.Reader) (*frontend.StatechartData, error) {
+			scdata, errs := process(r, l.includer)
+			return scdata, errors.Join(errs...)
+		})
+		if err != nil {
+			l.errs = append(l.errs, fmt.Errorf("line %d, char %d: including %q: %w", pos.Line, pos.Column, path, err))
+			continue
+		}
+
+		// Already included elsewhere.
+		if included == nil {
+			continue
+		}
+		l.scdata.Merge(included, parent)
+	}
+}
+
+// lowerTransitions adds |transitions|. |from| is the state they are declared within, if any.
+func (l *lowerer) lowerTransitions(transitions []*frontend.TransitionData, from string, node *yaml.Node) {
+	items := sequenceItems(node)
+	for i, tdata := range transitions {
+		tdata.Index = len(l.scdata.Transitions)
+		tdata.Pos = itemPosition(items, i)
+
+		if from != "" {
+			if tdata.From != "" && tdata.From != from {
+				l.errorf(tdata.Pos, "transition declared within state %q has %q as source", from, tdata.From)
+			}
+			tdata.From = from
+		}
+		l.scdata.Transitions = append(l.scdata.Transitions, tdata)
+	}
+}
+
+func nodePosition(node *yaml.Node) frontend.Position {
+	if node == nil {
+		return frontend.Position{}
+	}
+	return frontend.Position{Line: node.Line, Column: node.Column}
+}
+
+func itemNode(items []*yaml.Node, i int) *yaml.Node {
+	if i < len(items) {
+		return items[i]
+	}
+	return nil
+}
+
+func itemPosition(items []*yaml.Node, i int) frontend.Position {
+	return nodePosition(itemNode(items, i))
+}
+
+// mappingValue returns the value of |key| within a mapping node. nil if not found.
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+
+	// Mapping nodes hold the keys and values interleaved.
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+func sequenceItems(node *yaml.Node) []*yaml.Node {
+	if node == nil || node.Kind != yaml.SequenceNode {
+		return nil
+	}
+	return node.Content
 }
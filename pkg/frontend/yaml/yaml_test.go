@@ -0,0 +1,249 @@
+package yaml
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/cristiandonosoc/gochart/pkg/frontend"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProcessSimple(t *testing.T) {
+	pos := func(line, column int) frontend.Position {
+		return frontend.Position{File: "testdata/simple.yaml", Line: line, Column: column}
+	}
+
+	want := &frontend.StatechartData{
+		Name: "Simple",
+		Triggers: []*frontend.TriggerData{
+			{Name: "Trigger1", ArgumentsString: "int foo, float bar", Index: 0, Pos: pos(3, 5)},
+			{Name: "Trigger2", Index: 1, Pos: pos(5, 5)},
+		},
+		States: []*frontend.StateData{
+			{Name: "StateA", Initial: true, DefaultEnter: true, DefaultExit: true, Index: 0, Pos: pos(7, 5)},
+			{Name: "StateB", Initial: true, Parent: "StateA", Index: 1, Pos: pos(11, 5)},
+			{Name: "StateC", Parent: "StateA", Index: 2, Pos: pos(14, 5)},
+		},
+		Transitions: []*frontend.TransitionData{
+			{From: "StateB", To: "StateC", Trigger: "Trigger1", Index: 0, Pos: pos(17, 5)},
+		},
+		Pos: pos(1, 7),
+	}
+
+	yf := NewYamlFrontend()
+	got, err := yf.ProcessFromFile("testdata/simple.yaml")
+	require.NoError(t, err)
+	assert.Equal(t, want, got)
+}
+
+func TestProcessNested(t *testing.T) {
+	yf := NewYamlFrontend()
+	flat, err := yf.ProcessFromFile("testdata/simple.yaml")
+	require.NoError(t, err)
+	nested, err := yf.ProcessFromFile("testdata/nested.yaml")
+	require.NoError(t, err)
+
+	// Both forms produce the same data, other than the positions.
+	clearPositions := func(scdata *frontend.StatechartData) {
+		scdata.Pos = frontend.Position{}
+		for _, tdata := range scdata.Triggers {
+			tdata.Pos = frontend.Position{}
+		}
+		for _, sdata := range scdata.States {
+			sdata.Pos = frontend.Position{}
+		}
+		for _, tdata := range scdata.Transitions {
+			tdata.Pos = frontend.Position{}
+		}
+	}
+	clearPositions(flat)
+	clearPositions(nested)
+	assert.Equal(t, flat, nested)
+}
+
+func TestProcessMixed(t *testing.T) {
+	input := `
+name: Mixed
+states:
+  - name: A
+    initial: true
+    states:
+      - name: A1
+        initial: true
+        transitions:
+          - to: A2
+      - name: A2
+  - name: A3
+    parent: A
+transitions:
+  - from: A2
+    to: A3
+`
+
+	yf := NewYamlFrontend()
+	got, err := yf.Process(strings.NewReader(input))
+	require.NoError(t, err)
+
+	var states []string
+	for _, sdata := range got.States {
+		states = append(states, fmt.Sprintf("%s (%s) %d:%d", sdata.Name, sdata.Parent, sdata.Pos.Line, sdata.Pos.Column))
+	}
+	assert.Equal(t, []string{"A () 4:5", "A1 (A) 7:9", "A2 (A) 11:9", "A3 (A) 12:5"}, states)
+
+	var transitions []string
+	for _, tdata := range got.Transitions {
+		transitions = append(transitions, fmt.Sprintf("%s > %s %d:%d", tdata.From, tdata.To, tdata.Pos.Line, tdata.Pos.Column))
+	}
+	assert.Equal(t, []string{"A1 > A2 10:13", "A2 > A3 15:5"}, transitions)
+}
+
+func TestProcessIncludes(t *testing.T) {
+	pos := func(file string, line, column int) frontend.Position {
+		return frontend.Position{File: "testdata/include/" + file, Line: line, Column: column}
+	}
+
+	// The shared triggers are included by both the player and its movement, but merged only once.
+	want := &frontend.StatechartData{
+		Name: "Player",
+		Triggers: []*frontend.TriggerData{
+			{Name: "Move", ArgumentsString: "float speed", Index: 0, Pos: pos("triggers.yaml", 2, 5)},
+			{Name: "Stop", Index: 1, Pos: pos("triggers.yaml", 4, 5)},
+			{Name: "Die", Index: 2, Pos: pos("player.yaml", 4, 5)},
+		},
+		States: []*frontend.StateData{
+			{Name: "Alive", Initial: true, Index: 0, Pos: pos("player.yaml", 6, 5)},
+			{Name: "Idle", Initial: true, Parent: "Alive", Index: 1, Pos: pos("movement.yaml", 3, 5)},
+			{Name: "Walking", Parent: "Alive", Index: 2, Pos: pos("movement.yaml", 8, 5)},
+			{Name: "Dead", Final: true, Index: 3, Pos: pos("player.yaml", 12, 5)},
+		},
+		Transitions: []*frontend.TransitionData{
+			{From: "Alive", To: "Dead", Trigger: "Die", Index: 0, Pos: pos("player.yaml", 10, 9)},
+			{From: "Idle", To: "Walking", Trigger: "Move", Index: 1, Pos: pos("movement.yaml", 6, 9)},
+			{From: "Walking", To: "Idle", Trigger: "Stop", Index: 2, Pos: pos("movement.yaml", 10, 9)},
+		},
+		Pos: pos("player.yaml", 1, 7),
+	}
+
+	yf := NewYamlFrontend()
+	got, err := yf.ProcessFromFile("testdata/include/player.yaml")
+	require.NoError(t, err)
+	assert.Equal(t, want, got)
+}
+
+func TestProcessIncludeErrors(t *testing.T) {
+	testcases := []struct {
+		path    string
+		wantErr string
+	}{
+		{
+			path: "testdata/include/cycle_a.yaml",
+			wantErr: `line 2, char 11: including "cycle_b.yaml": testdata/include/cycle_b.yaml: ` +
+				`line 2, char 5: including "cycle_a.yaml": include cycle: ` +
+				`testdata/include/cycle_a.yaml -> testdata/include/cycle_b.yaml -> testdata/include/cycle_a.yaml`,
+		},
+		{
+			path: "testdata/include/broken.yaml",
+			wantErr: `line 4, char 15: including "broken_child.yaml": testdata/include/broken_child.yaml: ` +
+				`unmarshalling yaml: yaml: unmarshal errors:` + "\n" + `  line 3: field intial not found`,
+		},
+		{
+			path:    "testdata/include/missing.yaml",
+			wantErr: `reading "testdata/include/missing.yaml"`,
+		},
+		{
+			// The second include would leave the states of movement out of Enemy.
+			path: "testdata/include/two_parents.yaml",
+			wantErr: `line 7, char 15: including "movement.yaml": "testdata/include/movement.yaml" is already included at ` +
+				`testdata/include/two_parents.yaml:5:15, and the states it defines can only be included once`,
+		},
+	}
+
+	for _, tc := range testcases {
+		yf := NewYamlFrontend()
+		_, err := yf.ProcessFromFile(tc.path)
+		if assert.Error(t, err, tc.path) {
+			assert.Contains(t, err.Error(), tc.wantErr, tc.path)
+		}
+	}
+}
+
+func TestProcessErrors(t *testing.T) {
+	testcases := []struct {
+		name    string
+		input   string
+		wantErr string
+	}{
+		{
+			name: "unknown state key",
+			input: `
+name: Test
+states:
+  - name: A
+    intial: true
+`,
+			wantErr: "line 5: field intial not found",
+		},
+		{
+			name: "unknown top level key",
+			input: `
+name: Test
+transitons:
+  - from: A
+    to: B
+`,
+			wantErr: "line 3: field transitons not found",
+		},
+		{
+			name: "wrong type",
+			input: `
+name: Test
+states:
+  - name: A
+    initial: maybe
+`,
+			wantErr: "line 5: cannot unmarshal !!str `maybe` into bool",
+		},
+		{
+			name: "conflicting parent",
+			input: `
+name: Test
+states:
+  - name: A
+    states:
+      - name: B
+        parent: C
+`,
+			wantErr: `line 6, char 9: state "B" is nested within "A" but has "C" as parent`,
+		},
+		{
+			name: "conflicting source",
+			input: `
+name: Test
+states:
+  - name: A
+    transitions:
+      - from: B
+        to: A
+`,
+			wantErr: `line 6, char 9: transition declared within state "A" has "B" as source`,
+		},
+		{
+			name:    "empty",
+			input:   "",
+			wantErr: "empty input",
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			yf := NewYamlFrontend()
+			_, err := yf.Process(strings.NewReader(tc.input))
+			if assert.Error(t, err) {
+				assert.Contains(t, err.Error(), tc.wantErr)
+			}
+		})
+	}
+}
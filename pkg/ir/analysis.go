@@ -0,0 +1,122 @@
+package ir
+
+// This file holds the analysis over a valid statechart. It looks for things that do not make the
+// statechart invalid, but most likely are mistakes (eg. leftovers from a refactor), and reports
+// them as warnings.
+//
+// Guards are not evaluated, so every guarded transition is assumed to be possibly taken.
+
+// analyze runs all the analyses over the statechart. Requires the transitions to be resolved.
+func analyze(dc *diagnosticCollector, sc *Statechart) {
+	reachable := reachableStates(sc)
+	analyzeReachability(dc, sc, reachable)
+	analyzeUnusedTriggers(dc, sc)
+	analyzeDeadStates(dc, sc, reachable)
+}
+
+// reachableStates returns the states that can become active at some point, starting from the
+// initial configuration and following every transition.
+func reachableStates(sc *Statechart) map[*State]bool {
+	reachable := make(map[*State]bool)
+
+	var pending []*State
+	markReachable := func(states []*State) {
+		for _, state := range states {
+			if !reachable[state] {
+				reachable[state] = true
+				pending = append(pending, state)
+			}
+		}
+	}
+
+	markReachable(sc.InitialEntries())
+	for len(pending) > 0 {
+		state := pending[0]
+		pending = pending[1:]
+
+		for _, transition := range state.Transitions {
+			markReachable(transition.Entries)
+
+			// History can only restore states that were active before, which are already reachable.
+			// Without a record, the parent is entered by default.
+			if transition.TargetsHistory() {
+				markReachable(transition.To.Parent.DefaultEntries())
+			}
+		}
+	}
+
+	return reachable
+}
+
+// analyzeReachability reports the states that can never become active. Only the outermost
+// unreachable state of a subtree is reported, as its substates are unreachable as well.
+func analyzeReachability(dc *diagnosticCollector, sc *Statechart, reachable map[*State]bool) {
+	initial := sc.InitialState()
+	for _, state := range sc.DocumentOrder() {
+		// History states never become active.
+		if state.IsHistory() || reachable[state] {
+			continue
+		}
+
+		if state.Parent != nil && !reachable[state.Parent] {
+			continue
+		}
+
+		dc.warningf(state.frontendData.Pos, "state %q is unreachable from the initial state %q",
+			state.Name, initial.Name)
+	}
+}
+
+// analyzeUnusedTriggers reports the triggers that no transition or reaction uses.
+func analyzeUnusedTriggers(dc *diagnosticCollector, sc *Statechart) {
+	used := make(map[*Trigger]bool)
+	for _, state := range sc.States {
+		for _, reaction := range state.EnterReactions {
+			used[reaction.Trigger] = true
+		}
+		for _, reaction := range state.ExitReactions {
+			used[reaction.Trigger] = true
+		}
+	}
+	for _, transition := range sc.Transitions {
+		if transition.Trigger != nil {
+			used[transition.Trigger] = true
+		}
+	}
+
+	for _, trigger := range sc.Triggers {
+		if !used[trigger] {
+			dc.warningf(trigger.frontendData.Pos, "trigger %q is never used by any transition or reaction",
+				trigger.Name)
+		}
+	}
+}
+
+// analyzeDeadStates reports the reachable atomic states that, once entered, can never be exited
+// and are not marked final. A state can be exited by any transition of itself or its ancestors that
+// has it among its exits.
+func analyzeDeadStates(dc *diagnosticCollector, sc *Statechart, reachable map[*State]bool) {
+	for _, state := range sc.DocumentOrder() {
+		if !state.IsAtomic() || state.IsHistory() || state.Final || !reachable[state] {
+			continue
+		}
+
+		if !hasWayOut(state) {
+			dc.warningf(state.frontendData.Pos, "state %q has no way out and is not marked final", state.Name)
+		}
+	}
+}
+
+func hasWayOut(state *State) bool {
+	for current := state; current != nil; current = current.Parent {
+		for _, transition := range current.Transitions {
+			for _, exit := range transition.Exits {
+				if exit == state {
+					return true
+				}
+			}
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,113 @@
+package ir
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/cristiandonosoc/gochart/pkg/frontend/gochart_lang"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func processGochartLang(t *testing.T, input string) (*Statechart, error) {
+	t.Helper()
+
+	gf := gochart_lang.NewGochartLangFrontend()
+	scdata, err := gf.Process(strings.NewReader(input))
+	require.NoError(t, err)
+
+	return ProcessStatechartData(scdata)
+}
+
+func TestAnalysis(t *testing.T) {
+	input := `
+statechart Analysis {
+	trigger Start
+	trigger Stop
+	trigger Unused
+	trigger Hit
+
+	state Idle {
+		initial
+		transition Running { trigger Start }
+	}
+
+	state Running {
+		exit_reaction Hit
+		transition Running { trigger Hit internal }
+		transition Stuck { trigger Stop }
+	}
+
+	state Stuck {}
+
+	state Done {
+		final
+	}
+
+	state Orphan {
+		state OrphanChild { initial }
+		transition Idle { trigger Start }
+	}
+}`
+
+	sc, err := processGochartLang(t, input)
+	require.NoError(t, err)
+
+	got := make([]string, 0, len(sc.Diagnostics))
+	for _, d := range sc.Diagnostics {
+		assert.Equal(t, Severity_Warning, d.Severity)
+		got = append(got, d.String())
+	}
+
+	// Done is unreachable, but its being final does not hide that. The internal transition of
+	// Running does not count as a way out.
+	assert.Equal(t, []string{
+		`<input>:21:8: warning: state "Done" is unreachable from the initial state "Idle"`,
+		`<input>:25:8: warning: state "Orphan" is unreachable from the initial state "Idle"`,
+		`<input>:5:10: warning: trigger "Unused" is never used by any transition or reaction`,
+		`<input>:19:8: warning: state "Stuck" has no way out and is not marked final`,
+	}, got)
+}
+
+func TestAnalysisWayOutThroughAncestor(t *testing.T) {
+	input := `
+statechart Nested {
+	trigger Leave
+
+	state Outer {
+		initial
+		state Inner { initial }
+		transition Other { trigger Leave }
+	}
+
+	state Other {
+		final
+	}
+}`
+
+	sc, err := processGochartLang(t, input)
+	require.NoError(t, err)
+	assert.Empty(t, sc.Diagnostics)
+}
+
+func TestFinalStateErrors(t *testing.T) {
+	input := `
+statechart Final {
+	trigger Go
+
+	state A {
+		initial
+		final
+		state A1 { initial }
+		transition B { trigger Go }
+	}
+
+	state B {}
+}`
+
+	_, err := processGochartLang(t, input)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `error: final state "A" cannot have children`)
+	assert.Contains(t, err.Error(), `error: final state "A" cannot have outgoing transitions`)
+}
@@ -0,0 +1,104 @@
+package ir
+
+import (
+	"fmt"
+)
+
+// This file holds the detection of conflicting transitions: several transitions that could be
+// selected for the same trigger from the same active state. The statechart is still deterministic,
+// as the priority rule picks one of them (inner states first, and then declaration order), but the
+// ones that lose are most likely a mistake.
+// By default these are reported as warnings. Strict mode turns them into errors.
+
+// validateConflicts reports the transitions that are shadowed by others with higher priority.
+func validateConflicts(dc *diagnosticCollector, sc *Statechart, severity Severity) {
+	for _, state := range sc.DocumentOrder() {
+		for _, trigger := range transitionTriggers(state) {
+			transitions := transitionsOn(state, trigger)
+			validateConflictsWithinState(dc, state, trigger, transitions, severity)
+			validateConflictsWithAncestor(dc, state, trigger, transitions, severity)
+		}
+	}
+}
+
+// validateConflictsWithinState reports the transitions declared after an unguarded one on the same
+// trigger, as the unguarded one is always selected before them.
+func validateConflictsWithinState(dc *diagnosticCollector, state *State, trigger *Trigger,
+	transitions []*Transition, severity Severity) {
+	var unguarded *Transition
+	for _, transition := range transitions {
+		if unguarded != nil {
+			dc.add(severity, transition.frontendData.Pos,
+				"transition of %q to %q on %s is never taken: the unguarded transition to %q is declared before it",
+				state.Name, transition.To.Name, describeTrigger(trigger), unguarded.To.Name)
+			continue
+		}
+
+		if !transition.HasGuard() {
+			unguarded = transition
+		}
+	}
+}
+
+// validateConflictsWithAncestor reports when the closest ancestor with transitions on the same
+// trigger gets shadowed by |state|, as inner states have priority.
+func validateConflictsWithAncestor(dc *diagnosticCollector, state *State, trigger *Trigger,
+	transitions []*Transition, severity Severity) {
+	for ancestor := state.Parent; ancestor != nil; ancestor = ancestor.Parent {
+		if len(transitionsOn(ancestor, trigger)) == 0 {
+			continue
+		}
+
+		pos := transitions[0].frontendData.Pos
+		if hasUnguarded(transitions) {
+			dc.add(severity, pos,
+				"transitions of %q on %s are never taken while in %q: inner states have priority and %q has an unguarded one",
+				ancestor.Name, describeTrigger(trigger), state.Name, state.Name)
+		} else {
+			dc.add(severity, pos,
+				"transitions of %q on %s are only taken while in %q when the guards of %q fail, as inner states have priority",
+				ancestor.Name, describeTrigger(trigger), state.Name, state.Name)
+		}
+		return
+	}
+}
+
+// transitionTriggers returns the distinct triggers that the transitions of |state| use, in
+// declaration order. nil represents null transitions.
+func transitionTriggers(state *State) []*Trigger {
+	var triggers []*Trigger
+	seen := make(map[*Trigger]bool)
+	for _, transition := range state.Transitions {
+		if !seen[transition.Trigger] {
+			seen[transition.Trigger] = true
+			triggers = append(triggers, transition.Trigger)
+		}
+	}
+	return triggers
+}
+
+func transitionsOn(state *State, trigger *Trigger) []*Transition {
+	var transitions []*Transition
+	for _, transition := range state.Transitions {
+		if transition.Trigger == trigger {
+			transitions = append(transitions, transition)
+		}
+	}
+	return transitions
+}
+
+func hasUnguarded(transitions []*Transition) bool {
+	for _, transition := range transitions {
+		if !transition.HasGuard() {
+			return true
+		}
+	}
+	return false
+}
+
+func describeTrigger(trigger *Trigger) string {
+	if trigger == nil {
+		return "no trigger"
+	}
+	return fmt.Sprintf("trigger %q", trigger.Name)
+}
@@ -0,0 +1,88 @@
+package ir
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/cristiandonosoc/gochart/pkg/frontend/gochart_lang"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const conflictsInput = `
+statechart Conflicts {
+	trigger Go
+	trigger Back
+
+	state P {
+		initial
+		state A {
+			initial
+			transition B { trigger Go }
+			transition C { trigger Go guard Never }
+		}
+		state B {
+			transition A { trigger Back guard CanGoBack }
+		}
+		state C {}
+		transition Q { trigger Go }
+		transition Q { trigger Back }
+	}
+
+	state Q {
+		final
+	}
+}`
+
+func TestConflicts(t *testing.T) {
+	sc, err := processGochartLang(t, conflictsInput)
+	require.NoError(t, err)
+
+	var got []string
+	for _, d := range sc.Diagnostics {
+		assert.Equal(t, Severity_Warning, d.Severity)
+		got = append(got, d.Message)
+	}
+
+	assert.Equal(t, []string{
+		`transition of "A" to "C" on trigger "Go" is never taken: the unguarded transition to "B" is declared before it`,
+		`transitions of "P" on trigger "Go" are never taken while in "A": inner states have priority and "A" has an unguarded one`,
+		`transitions of "P" on trigger "Back" are only taken while in "B" when the guards of "B" fail, as inner states have priority`,
+	}, got)
+}
+
+func TestConflictsStrict(t *testing.T) {
+	gf := gochart_lang.NewGochartLangFrontend()
+	scdata, err := gf.Process(strings.NewReader(conflictsInput))
+	require.NoError(t, err)
+
+	_, err = ProcessStatechartData(scdata, WithStrict())
+	require.Error(t, err)
+	assert.True(t, strings.HasPrefix(err.Error(), "3 errors found:\n"))
+	assert.Contains(t, err.Error(), `<input>:11:15: error: transition of "A" to "C" on trigger "Go" is never taken`)
+}
+
+func TestNoConflictsAcrossRegions(t *testing.T) {
+	input := `
+statechart Regions {
+	trigger Go
+
+	state P {
+		initial
+		parallel
+		state R1 {
+			state R1A { initial transition R1B { trigger Go } }
+			state R1B { final }
+		}
+		state R2 {
+			state R2A { initial transition R2B { trigger Go } }
+			state R2B { final }
+		}
+	}
+}`
+
+	sc, err := processGochartLang(t, input)
+	require.NoError(t, err)
+	assert.Empty(t, sc.Diagnostics)
+}
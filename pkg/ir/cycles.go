@@ -0,0 +1,285 @@
+package ir
+
+import (
+	"sort"
+	"strings"
+)
+
+// This file holds the detection of cycles of null transitions. Null transitions are taken as soon
+// as their source is active, so a cycle of them would keep the statechart transitioning forever
+// without ever settling.
+//
+// After a null transition is taken, the atomic states it entered (either explicitly or through
+// initial substates) select their next null transition with the usual priority. A cycle where every
+// step is the only possible choice can never end, so it is an error. If some step depends on a
+// guard, the cycle only ends if the guards stop passing, so it is reported as a warning.
+
+// nullTransitionGraph links each null transition to the null transitions that could be taken right
+// after it.
+type nullTransitionGraph struct {
+	nodes []*Transition
+
+	// edges are all the transitions that could follow.
+	edges map[*Transition][]*Transition
+
+	// forcedEdges are the transitions that would always follow, as they are unguarded and the only
+	// candidate for some state entered.
+	forcedEdges map[*Transition][]*Transition
+}
+
+// validateNullTransitionCycles reports the cycles of null transitions. Requires the transitions to
+// be resolved.
+func validateNullTransitionCycles(dc *diagnosticCollector, sc *Statechart) {
+	graph := newNullTransitionGraph(sc)
+
+	// First we look for the cycles that will certainly happen.
+	inForcedCycle := make(map[*Transition]bool)
+	for _, cycle := range findCycles(graph.nodes, graph.forcedEdges) {
+		for _, transition := range cycle {
+			inForcedCycle[transition] = true
+		}
+
+		dc.errorf(cycle[0].frontendData.Pos, "null transitions form an infinite loop: %s", formatCycle(cycle))
+	}
+
+	// Then the ones that depend on guards, not reporting again the ones already found.
+CYCLE_LOOP:
+	for _, cycle := range findCycles(graph.nodes, graph.edges) {
+		for _, transition := range cycle {
+			if inForcedCycle[transition] {
+				continue CYCLE_LOOP
+			}
+		}
+
+		dc.warningf(cycle[0].frontendData.Pos,
+			"null transitions form a loop that only ends when their guards fail: %s", formatCycle(cycle))
+	}
+}
+
+func newNullTransitionGraph(sc *Statechart) *nullTransitionGraph {
+	graph := &nullTransitionGraph{
+		edges:       make(map[*Transition][]*Transition),
+		forcedEdges: make(map[*Transition][]*Transition),
+	}
+
+	for _, transition := range sc.Transitions {
+		if transition.IsNullTransition() {
+			graph.nodes = append(graph.nodes, transition)
+		}
+	}
+
+	for _, transition := range graph.nodes {
+		seen := make(map[*Transition]bool)
+		for _, atomic := range enteredAtomics(transition) {
+			candidates := nullCandidates(atomic)
+			for _, candidate := range candidates {
+				if !seen[candidate] {
+					seen[candidate] = true
+					graph.edges[transition] = append(graph.edges[transition], candidate)
+				}
+			}
+
+			if len(candidates) == 1 && !candidates[0].HasGuard() {
+				graph.forcedEdges[transition] = append(graph.forcedEdges[transition], candidates[0])
+			}
+		}
+	}
+
+	return graph
+}
+
+// enteredAtomics returns the atomic states that could be active and evaluating null transitions
+// right after taking |transition|.
+func enteredAtomics(transition *Transition) []*State {
+	states := append([]*State{}, transition.Entries...)
+
+	// Restoring history could enter any of the substates.
+	if transition.TargetsHistory() {
+		states = append(states, transition.To.Parent.Descendants()...)
+	}
+
+	// Internal transitions to their own source do not change anything, so its active substates
+	// evaluate again.
+	if len(transition.Entries) == 0 {
+		states = append([]*State{transition.From}, transition.From.Descendants()...)
+	}
+
+	var atomics []*State
+	seen := make(map[*State]bool)
+	for _, state := range states {
+		if state.IsAtomic() && !state.IsHistory() && !seen[state] {
+			seen[state] = true
+			atomics = append(atomics, state)
+		}
+	}
+	return atomics
+}
+
+// nullCandidates returns the null transitions that an active atomic state could take, in priority
+// order: inner states first and then declaration order. Nothing after an unguarded transition can
+// be taken.
+func nullCandidates(atomic *State) []*Transition {
+	var candidates []*Transition
+	for current := atomic; current != nil; current = current.Parent {
+		for _, transition := range current.Transitions {
+			if !transition.IsNullTransition() {
+				continue
+			}
+
+			candidates = append(candidates, transition)
+			if !transition.HasGuard() {
+				return candidates
+			}
+		}
+	}
+	return candidates
+}
+
+// findCycles returns a cycle for every strongly connected component of the graph that has one, in
+// the order of |nodes|.
+func findCycles(nodes []*Transition, edges map[*Transition][]*Transition) [][]*Transition {
+	var cycles [][]*Transition
+	for _, component := range stronglyConnectedComponents(nodes, edges) {
+		inComponent := make(map[*Transition]bool)
+		for _, node := range component {
+			inComponent[node] = true
+		}
+
+		// A single node is only a cycle if it follows itself.
+		start := component[0]
+		if cycle := shortestCycle(start, edges, inComponent); cycle != nil {
+			cycles = append(cycles, cycle)
+		}
+	}
+	return cycles
+}
+
+// stronglyConnectedComponents runs Tarjan's algorithm over the graph. Each component keeps the order
+// of |nodes|, and the components are sorted by their first node.
+func stronglyConnectedComponents(nodes []*Transition, edges map[*Transition][]*Transition) [][]*Transition {
+	order := make(map[*Transition]int)
+	for i, node := range nodes {
+		order[node] = i
+	}
+
+	index := make(map[*Transition]int)
+	lowlink := make(map[*Transition]int)
+	onStack := make(map[*Transition]bool)
+	var stack []*Transition
+	var components [][]*Transition
+
+	var visit func(node *Transition)
+	visit = func(node *Transition) {
+		index[node] = len(index)
+		lowlink[node] = index[node]
+		stack = append(stack, node)
+		onStack[node] = true
+
+		for _, next := range edges[node] {
+			if _, visited := index[next]; !visited {
+				visit(next)
+				if lowlink[next] < lowlink[node] {
+					lowlink[node] = lowlink[next]
+				}
+			} else if onStack[next] && index[next] < lowlink[node] {
+				lowlink[node] = index[next]
+			}
+		}
+
+		if lowlink[node] != index[node] {
+			return
+		}
+
+		var component []*Transition
+		for {
+			last := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			onStack[last] = false
+			component = append(component, last)
+			if last == node {
+				break
+			}
+		}
+		components = append(components, component)
+	}
+
+	for _, node := range nodes {
+		if _, visited := index[node]; !visited {
+			visit(node)
+		}
+	}
+
+	for _, component := range components {
+		sort.Slice(component, func(i, j int) bool {
+			return order[component[i]] < order[component[j]]
+		})
+	}
+	sort.Slice(components, func(i, j int) bool {
+		return order[components[i][0]] < order[components[j][0]]
+	})
+
+	return components
+}
+
+// shortestCycle returns the shortest path from |start| back to itself within the component, or nil
+// if there is none.
+func shortestCycle(start *Transition, edges map[*Transition][]*Transition,
+	inComponent map[*Transition]bool) []*Transition {
+	previous := make(map[*Transition]*Transition)
+	queue := []*Transition{start}
+	for len(queue) > 0 {
+		node := queue[0]
+		queue = queue[1:]
+
+		for _, next := range edges[node] {
+			if !inComponent[next] {
+				continue
+			}
+
+			if next == start {
+				// Rebuild the path walking backwards.
+				cycle := []*Transition{node}
+				for node != start {
+					node = previous[node]
+					cycle = append([]*Transition{node}, cycle...)
+				}
+				return cycle
+			}
+
+			if _, ok := previous[next]; !ok {
+				previous[next] = node
+				queue = append(queue, next)
+			}
+		}
+	}
+
+	return nil
+}
+
+// formatCycle describes the states a cycle goes through, eg. "A -> B/B1 -> A". Entering a
+// substate through initial descent is shown with "/", while continuing with a transition of an
+// ancestor of the state reached is shown with "(in ...)".
+func formatCycle(cycle []*Transition) string {
+	var sb strings.Builder
+	sb.WriteString(cycle[0].From.Name)
+	for i, transition := range cycle {
+		sb.WriteString(" -> ")
+		sb.WriteString(transition.To.Name)
+
+		next := cycle[(i+1)%len(cycle)]
+		if next.From == transition.To {
+			continue
+		}
+
+		if next.From.IsDescendantOf(transition.To) {
+			var path []string
+			for current := next.From; current != transition.To; current = current.Parent {
+				path = append([]string{current.Name}, path...)
+			}
+			sb.WriteString("/" + strings.Join(path, "/"))
+		} else {
+			sb.WriteString(" (in " + next.From.Name + ")")
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,135 @@
+package ir
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNullTransitionCycleErrors(t *testing.T) {
+	testcases := []struct {
+		name    string
+		input   string
+		wantErr string
+	}{
+		{
+			name: "direct cycle",
+			input: `
+statechart Test {
+	state A {
+		initial
+		transition B {}
+	}
+	state B {
+		transition A {}
+	}
+}`,
+			wantErr: "null transitions form an infinite loop: A -> B -> A",
+		},
+		{
+			name: "cycle through initial descent",
+			input: `
+statechart Test {
+	state A {
+		initial
+		transition P {}
+	}
+	state P {
+		state P1 {
+			initial
+			transition A {}
+		}
+	}
+}`,
+			wantErr: "null transitions form an infinite loop: A -> P/P1 -> A",
+		},
+		{
+			name: "cycle through an ancestor transition",
+			input: `
+statechart Test {
+	state P {
+		initial
+		state P1 {
+			initial
+			transition P2 {}
+		}
+		state P2 {}
+		transition Q {}
+	}
+	state Q {
+		transition P {}
+	}
+}`,
+			wantErr: "null transitions form an infinite loop: P -> Q -> P/P1 -> P2 (in P)",
+		},
+		{
+			name: "internal self transition",
+			input: `
+statechart Test {
+	state A {
+		initial
+		transition A { internal }
+	}
+}`,
+			wantErr: "null transitions form an infinite loop: A -> A",
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := processGochartLang(t, tc.input)
+			if assert.Error(t, err) {
+				assert.Contains(t, err.Error(), tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestNullTransitionCycleWithGuards(t *testing.T) {
+	input := `
+statechart Test {
+	state A {
+		initial
+		transition B {}
+	}
+	state B {
+		transition A { guard ShouldRetry }
+		transition C {}
+	}
+	state C {
+		final
+	}
+}`
+
+	sc, err := processGochartLang(t, input)
+	require.NoError(t, err)
+
+	if assert.Len(t, sc.Diagnostics, 1) {
+		assert.Equal(t, Severity_Warning, sc.Diagnostics[0].Severity)
+		assert.Equal(t, "null transitions form a loop that only ends when their guards fail: A -> B -> A",
+			sc.Diagnostics[0].Message)
+	}
+}
+
+func TestNullTransitionsWithoutCycle(t *testing.T) {
+	input := `
+statechart Test {
+	trigger Restart
+
+	state A {
+		initial
+		transition B {}
+	}
+	state B {
+		transition C {}
+	}
+	state C {
+		transition A { trigger Restart }
+	}
+}`
+
+	sc, err := processGochartLang(t, input)
+	require.NoError(t, err)
+	assert.Empty(t, sc.Diagnostics)
+}
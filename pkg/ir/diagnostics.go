@@ -0,0 +1,130 @@
+package ir
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/cristiandonosoc/gochart/pkg/frontend"
+)
+
+// Severity is how bad a diagnostic is.
+type Severity int
+
+const (
+	// Severity_Warning is a problem that does not prevent the statechart from being generated.
+	Severity_Warning Severity = iota
+
+	// Severity_Error is a problem that makes the statechart invalid.
+	Severity_Error
+)
+
+func (s Severity) String() string {
+	switch s {
+	case Severity_Warning:
+		return "warning"
+	case Severity_Error:
+		return "error"
+	}
+
+	return fmt.Sprintf("<invalid severity %d>", int(s))
+}
+
+// Diagnostic is a single problem found while processing a statechart.
+type Diagnostic struct {
+	Severity Severity
+
+	// Pos is where the offending element was defined, as reported by the frontend.
+	Pos     frontend.Position
+	Message string
+}
+
+func (d *Diagnostic) String() string {
+	if pos := d.Pos.String(); pos != "" {
+		return fmt.Sprintf("%s: %s: %s", pos, d.Severity, d.Message)
+	}
+	return fmt.Sprintf("%s: %s", d.Severity, d.Message)
+}
+
+// Diagnostics is a list of problems found while processing a statechart, in the order they were
+// found.
+type Diagnostics []*Diagnostic
+
+// HasErrors returns whether any of the diagnostics is an error.
+func (ds Diagnostics) HasErrors() bool {
+	for _, d := range ds {
+		if d.Severity == Severity_Error {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Errors returns only the diagnostics that are errors.
+func (ds Diagnostics) Errors() Diagnostics {
+	return ds.filter(Severity_Error)
+}
+
+// Warnings returns only the diagnostics that are warnings.
+func (ds Diagnostics) Warnings() Diagnostics {
+	return ds.filter(Severity_Warning)
+}
+
+func (ds Diagnostics) filter(severity Severity) Diagnostics {
+	var result Diagnostics
+	for _, d := range ds {
+		if d.Severity == severity {
+			result = append(result, d)
+		}
+	}
+	return result
+}
+
+func (ds Diagnostics) String() string {
+	lines := make([]string, 0, len(ds))
+	for _, d := range ds {
+		lines = append(lines, d.String())
+	}
+	return strings.Join(lines, "\n")
+}
+
+// DiagnosticsError is returned when processing a statechart finds errors. It holds every
+// diagnostic found, so that all the problems can be reported at once.
+type DiagnosticsError struct {
+	Diagnostics Diagnostics
+}
+
+func (de *DiagnosticsError) Error() string {
+	errors := de.Diagnostics.Errors()
+	if len(errors) == 1 {
+		return errors[0].String()
+	}
+
+	return fmt.Sprintf("%d errors found:\n%s", len(errors), errors.String())
+}
+
+// diagnosticCollector accumulates diagnostics while processing a statechart, so that processing can
+// continue after a problem is found.
+type diagnosticCollector struct {
+	diagnostics Diagnostics
+}
+
+func (dc *diagnosticCollector) errorf(pos frontend.Position, format string, args ...any) {
+	dc.add(Severity_Error, pos, format, args...)
+}
+
+func (dc *diagnosticCollector) warningf(pos frontend.Position, format string, args ...any) {
+	dc.add(Severity_Warning, pos, format, args...)
+}
+
+func (dc *diagnosticCollector) add(severity Severity, pos frontend.Position, format string, args ...any) {
+	dc.diagnostics = append(dc.diagnostics, &Diagnostic{
+		Severity: severity,
+		Pos:      pos,
+		Message:  fmt.Sprintf(format, args...),
+	})
+}
+
+func (dc *diagnosticCollector) hasErrors() bool {
+	return dc.diagnostics.HasErrors()
+}
@@ -0,0 +1,174 @@
+package ir
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/cristiandonosoc/gochart/pkg/frontend"
+	"github.com/cristiandonosoc/gochart/pkg/frontend/gochart_lang"
+	"github.com/cristiandonosoc/gochart/pkg/frontend/json"
+	"github.com/cristiandonosoc/gochart/pkg/frontend/yaml"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiagnosticsReportEverything(t *testing.T) {
+	input := `
+statechart Broken {
+	trigger Go
+	trigger Go
+
+	state A {
+		initial
+		enter_reaction Stop
+		transition Missing { trigger Go }
+	}
+
+	state B {
+		initial
+		state B1 {}
+	}
+}`
+
+	gf := gochart_lang.NewGochartLangFrontend()
+	scdata, err := gf.Process(strings.NewReader(input))
+	require.NoError(t, err)
+
+	_, err = ProcessStatechartData(scdata)
+	require.Error(t, err)
+
+	var de *DiagnosticsError
+	require.True(t, errors.As(err, &de))
+
+	got := make([]string, 0, len(de.Diagnostics))
+	for _, d := range de.Diagnostics {
+		assert.Equal(t, Severity_Error, d.Severity)
+		got = append(got, d.String())
+	}
+
+	assert.Equal(t, []string{
+		`<input>:4:10: error: trigger "Go" defined twice`,
+		`<input>:6:8: error: state "A": cannot find enter reaction trigger "Stop"`,
+		`<input>:9:14: error: transition Go: A > Missing: cannot find to state "Missing"`,
+		`<input>:12:8: error: state "B" marked as initial when "A" is already the initial state`,
+		`<input>:12:8: error: state "B" has no initial child`,
+	}, got)
+	assert.True(t, strings.HasPrefix(err.Error(), "5 errors found:\n"))
+}
+
+func TestDiagnosticsUnknownParent(t *testing.T) {
+	input := `
+name: Test
+states:
+  - name: A
+    initial: true
+  - name: B
+    parent: Missing
+`
+
+	yf := yaml.NewYamlFrontend()
+	scdata, err := yf.Process(strings.NewReader(input))
+	require.NoError(t, err)
+
+	scdata.SetFile("test.yaml")
+
+	_, err = ProcessStatechartData(scdata)
+	assert.EqualError(t, err, `test.yaml:6:5: error: state "B" has unexistent parent state "Missing"`)
+}
+
+func TestDiagnosticsJsonPositions(t *testing.T) {
+	input := `{
+  "name": "Test",
+  "triggers": [{ "name": "Go" }],
+  "states": [
+    { "name": "A", "initial": true },
+    { "name": "B", "parent": "Missing" }
+  ],
+  "transitions": [
+    { "from": "A", "to": "C", "trigger": "Go" }
+  ]
+}`
+
+	jf := json.NewJsonFrontend()
+	scdata, err := jf.Process(strings.NewReader(input))
+	require.NoError(t, err)
+
+	scdata.SetFile("test.json")
+
+	_, err = ProcessStatechartData(scdata)
+	assert.EqualError(t, err, `2 errors found:
+test.json:6:5: error: state "B" has unexistent parent state "Missing"
+test.json:9:5: error: transition Go: A > C: cannot find to state "C"`)
+}
+
+func TestDiagnosticsParentCycle(t *testing.T) {
+	input := `
+name: Test
+triggers:
+  - name: Go
+states:
+  - name: Root
+    initial: true
+  - name: A
+    parent: B
+  - name: B
+    parent: A
+  - name: C
+    parent: C
+transitions:
+  - from: Root
+    to: A
+    trigger: Go
+`
+
+	yf := yaml.NewYamlFrontend()
+	scdata, err := yf.Process(strings.NewReader(input))
+	require.NoError(t, err)
+
+	scdata.SetFile("test.yaml")
+
+	// Before parent cycles were reported, resolving the transition to A never finished.
+	_, err = ProcessStatechartData(scdata)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `test.yaml:10:5: error: state "B" has a parent cycle: B -> A -> B`)
+	assert.Contains(t, err.Error(), `test.yaml:12:5: error: state "C" has a parent cycle: C -> C`)
+}
+
+func TestDiagnosticsReservedNames(t *testing.T) {
+	input := `
+statechart Reserved {
+	trigger None
+
+	state None {
+		initial
+		transition None { trigger None }
+	}
+}`
+
+	gf := gochart_lang.NewGochartLangFrontend()
+	scdata, err := gf.Process(strings.NewReader(input))
+	require.NoError(t, err)
+
+	_, err = ProcessStatechartData(scdata)
+	assert.EqualError(t, err, strings.Join([]string{
+		"2 errors found:",
+		`<input>:3:10: error: trigger "None": "None" is reserved for the generated code`,
+		`<input>:5:8: error: state "None": "None" is reserved for the generated code`,
+	}, "\n"))
+}
+
+func TestDiagnosticString(t *testing.T) {
+	d := &Diagnostic{
+		Severity: Severity_Warning,
+		Message:  "something odd",
+	}
+	assert.Equal(t, "warning: something odd", d.String())
+
+	d.Pos = frontend.Position{File: "chart.gochart", Line: 3}
+	assert.Equal(t, "chart.gochart:3: warning: something odd", d.String())
+
+	d.Pos.Column = 7
+	assert.Equal(t, "chart.gochart:3:7: warning: something odd", d.String())
+}
@@ -2,6 +2,7 @@ package ir
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/cristiandonosoc/gochart/pkg/frontend"
 
@@ -9,7 +10,11 @@ import (
 )
 
 // inputHandler is a helper struct to keep running state while we process the frontend input.
+// Problems are collected as diagnostics instead of stopping at the first one, so that all of them
+// can be reported at once.
 type inputHandler struct {
+	diagnosticCollector
+
 	scdata *frontend.StatechartData
 
 	triggers   []*Trigger
@@ -20,30 +25,47 @@ type inputHandler struct {
 	stateMap   map[string]*State
 }
 
-func ProcessStatechartData(scdata *frontend.StatechartData) (*Statechart, error) {
-	ih := inputHandler{
-		scdata:     scdata,
-		triggerMap: make(map[string]*Trigger),
-		stateMap:   make(map[string]*State),
-	}
+type Options struct {
+	// Strict turns into errors the problems that do not make the statechart invalid but make its
+	// behavior surprising, such as conflicting transitions.
+	Strict bool
+}
 
-	if err := ih.collectTriggers(); err != nil {
-		return nil, fmt.Errorf("collecting triggers: %w", err)
+type Option func(*Options)
+
+// WithStrict enables strict mode (see Options.Strict).
+func WithStrict() Option {
+	return func(options *Options) {
+		options.Strict = true
 	}
+}
 
-	if err := ih.collectStates(); err != nil {
-		return nil, fmt.Errorf("collecting states: %w", err)
+// ProcessStatechartData validates the frontend data and converts it into a statechart. If any
+// problem is found, the returned error is a *DiagnosticsError holding all of them.
+func ProcessStatechartData(scdata *frontend.StatechartData, opts ...Option) (*Statechart, error) {
+	options := &Options{}
+	for _, opt := range opts {
+		opt(options)
 	}
 
-	if err := ih.collectTransitions(); err != nil {
-		return nil, fmt.Errorf("collecting transitions: %w", err)
+	ih := inputHandler{
+		scdata:     scdata,
+		triggerMap: make(map[string]*Trigger),
+		stateMap:   make(map[string]*State),
 	}
 
-	if err := validate(&ih); err != nil {
-		return nil, fmt.Errorf("validating input: %w", err)
+	ih.collectTriggers()
+	ih.collectStates()
+	ih.collectTransitions()
+	validate(&ih)
+
+	if ih.hasErrors() {
+		return nil, &DiagnosticsError{
+			Diagnostics: ih.diagnostics,
+		}
 	}
 
-	return &Statechart{
+	sc := &Statechart{
 		Name:         scdata.Name,
 		Roots:        ih.rootStates,
 		Triggers:     ih.triggers,
@@ -51,126 +73,184 @@ func ProcessStatechartData(scdata *frontend.StatechartData) (*Statechart, error)
 		TriggerMap:   ih.triggerMap,
 		StateMap:     ih.stateMap,
 		frontendData: scdata,
-	}, nil
-}
+	}
 
-func (ih *inputHandler) collectTriggers() error {
-	triggers := make([]*Trigger, 0, len(ih.scdata.Triggers))
+	for _, state := range sc.DocumentOrder() {
+		sc.Transitions = append(sc.Transitions, state.Transitions...)
+	}
+	resolveTransitions(sc)
+
+	// Some problems can only be found once we know how transitions behave.
+	validateNullTransitionCycles(&ih.diagnosticCollector, sc)
+
+	conflictSeverity := Severity_Warning
+	if options.Strict {
+		conflictSeverity = Severity_Error
+	}
+	validateConflicts(&ih.diagnosticCollector, sc, conflictSeverity)
+	if ih.hasErrors() {
+		return nil, &DiagnosticsError{
+			Diagnostics: ih.diagnostics,
+		}
+	}
+
+	analyze(&ih.diagnosticCollector, sc)
+	sc.Diagnostics = ih.diagnostics
+
+	return sc, nil
+}
 
+func (ih *inputHandler) collectTriggers() {
 	for _, tdata := range ih.scdata.Triggers {
-		trigger, err := ih.createTrigger(tdata)
-		if err != nil {
-			return fmt.Errorf("creating trigger %q: %w", tdata.Name, err)
+		// We make sure that the trigger doesn't exist already.
+		if _, ok := ih.triggerMap[tdata.Name]; ok {
+			ih.errorf(tdata.Pos, "trigger %q defined twice", tdata.Name)
+			continue
 		}
 
-		triggers = append(triggers, trigger)
+		// We still keep the trigger, so that its usages do not get reported as missing.
+		if err := validateName(tdata.Name); err != nil {
+			ih.errorf(tdata.Pos, "trigger %q: %v", tdata.Name, err)
+		}
+
+		trigger := ih.createTrigger(tdata)
+		ih.triggers = append(ih.triggers, trigger)
 		ih.triggerMap[trigger.Name] = trigger
 	}
-
-	ih.triggers = triggers
-	return nil
 }
 
-func (ih *inputHandler) createTrigger(tdata *frontend.TriggerData) (*Trigger, error) {
-	// We make sure that the trigger doesn't exist already.
-	if _, ok := ih.triggerMap[tdata.Name]; ok {
-		return nil, fmt.Errorf("trigger %q defined twice", tdata.Name)
+func (ih *inputHandler) createTrigger(tdata *frontend.TriggerData) *Trigger {
+	trigger := &Trigger{
+		Name:         tdata.Name,
+		frontendData: tdata,
 	}
 
 	// Parse the arguments.
-	var args []*TriggerArgument
 	if tdata.ArgumentsString != "" {
 		// For now we only support C++, but we could support other languages as well if needed.
-		parsedArgs, err := ParseCppArguments(tdata.ArgumentsString)
+		args, err := ParseCppArguments(tdata.ArgumentsString)
 		if err != nil {
-			return nil, fmt.Errorf("parsing arguments for trigger %q: %w", tdata.Name, err)
+			// We still keep the trigger, so that its usages do not get reported as missing.
+			ih.errorf(tdata.Pos, "parsing arguments for trigger %q: %v", tdata.Name, err)
 		}
-		args = parsedArgs
+		trigger.Args = args
 	}
 
-	return &Trigger{
-		Name:         tdata.Name,
-		Args:         args,
-		frontendData: tdata,
-	}, nil
+	return trigger
 }
 
-func (ih *inputHandler) collectStates() error {
+func (ih *inputHandler) collectStates() {
 	// We first create all the states and track its associated data.
-	var states []*State
-	stateMap := make(map[string]*State)
 	for _, statedata := range ih.scdata.States {
 		// The state should not exist.
-		if _, ok := stateMap[statedata.Name]; ok {
-			return fmt.Errorf("state %q already exists", statedata.Name)
+		if _, ok := ih.stateMap[statedata.Name]; ok {
+			ih.errorf(statedata.Pos, "state %q already exists", statedata.Name)
+			continue
+		}
+
+		if err := validateName(statedata.Name); err != nil {
+			ih.errorf(statedata.Pos, "state %q: %v", statedata.Name, err)
+		}
+
+		history, err := parseHistoryKind(statedata.History)
+		if err != nil {
+			ih.errorf(statedata.Pos, "state %q: %v", statedata.Name, err)
 		}
 
 		// For now, we simply create the state. Parenthood will be set on a second pass.
 		state := &State{
 			Name:         statedata.Name,
 			Initial:      statedata.Initial,
+			Parallel:     statedata.Parallel,
+			History:      history,
+			Final:        statedata.Final,
 			frontendData: statedata,
 		}
-		states = append(states, state)
-		stateMap[statedata.Name] = state
+		ih.states = append(ih.states, state)
+		ih.stateMap[statedata.Name] = state
 	}
 
 	// Now we check for parenthood.
-	var roots []*State
-	for name, state := range stateMap {
+	// We iterate over the ordered states so that children keep the order in which they were defined.
+	for _, state := range ih.states {
 		// If the parent name is null, it means that this is a root state.
 		if state.frontendData.Parent == "" {
-			roots = append(roots, state)
+			ih.rootStates = append(ih.rootStates, state)
 			continue
 		}
 
 		// Search for the parent and mark it as a child of the other.
-		parent, ok := stateMap[state.frontendData.Parent]
+		// States with an unknown parent are left out of the tree.
+		parent, ok := ih.stateMap[state.frontendData.Parent]
 		if !ok {
-			return fmt.Errorf("state %q has unexistent parent state %q", name, state.frontendData.Parent)
+			ih.errorf(state.frontendData.Pos, "state %q has unexistent parent state %q",
+				state.Name, state.frontendData.Parent)
+			continue
 		}
 
 		// The parent should not have have this state already.
 		// We mark the parent <=> child relationship.
 		if parent.IsParentOf(state) {
-			return fmt.Errorf("state %q already has state %q as child", parent.Name, state.Name)
+			ih.errorf(state.frontendData.Pos, "state %q already has state %q as child", parent.Name, state.Name)
+			continue
+		}
+
+		// Parenthood has to form a tree, as everything walking up the ancestors would loop forever
+		// otherwise. The links made so far are acyclic, so we only need to check that the state is
+		// not an ancestor of (or the same as) its parent.
+		if parent == state || parent.IsDescendantOf(state) {
+			ih.errorf(state.frontendData.Pos, "state %q has a parent cycle: %s",
+				state.Name, parentCycle(state, parent))
+			continue
 		}
 		parent.Children = append(parent.Children, state)
 		state.Parent = parent
 	}
 
 	// We collect the transition reactions.
-	for name, state := range stateMap {
+	for _, state := range ih.states {
 		// Collect the enter reactions.
 		state.DefaultEnter = state.frontendData.DefaultEnter
-		enters, err := ih.collectReactions(state.frontendData.EnterReactionTriggers)
-		if err != nil {
-			return fmt.Errorf("state %q: collecting enter reactions: %w", name, err)
-		}
-		state.EnterReactions = enters
+		state.EnterReactions = ih.collectReactions(state, "enter", state.frontendData.EnterReactionTriggers)
 
 		// Collect the exit reactions.
 		state.DefaultExit = state.frontendData.DefaultExit
-		exits, err := ih.collectReactions(state.frontendData.ExitReactionTriggers)
-		if err != nil {
-			return fmt.Errorf("state %q: collecting exit reactions: %w", name, err)
-		}
-		state.ExitReactions = exits
+		state.ExitReactions = ih.collectReactions(state, "exit", state.frontendData.ExitReactionTriggers)
+	}
+}
+
+func parseHistoryKind(history string) (HistoryKind, error) {
+	switch history {
+	case "":
+		return History_None, nil
+	case "shallow":
+		return History_Shallow, nil
+	case "deep":
+		return History_Deep, nil
 	}
 
-	ih.rootStates = roots
-	ih.states = states
-	ih.stateMap = stateMap
+	return History_None, fmt.Errorf("unknown history kind %q, expected \"shallow\" or \"deep\"", history)
+}
+
+// parentCycle describes the cycle that giving |state| the parent |parent| would make, going up from
+// |state| (eg. "A -> B -> A").
+func parentCycle(state, parent *State) string {
+	names := []string{state.Name}
+	for current := parent; current != state; current = current.Parent {
+		names = append(names, current.Name)
+	}
+	names = append(names, state.Name)
 
-	return nil
+	return strings.Join(names, " -> ")
 }
 
-func (ih *inputHandler) collectReactions(triggerNames []string) ([]*StateReaction, error) {
+func (ih *inputHandler) collectReactions(state *State, kind string, triggerNames []string) []*StateReaction {
 	var triggers []*Trigger
 	for _, triggerName := range triggerNames {
 		trigger, ok := ih.triggerMap[triggerName]
 		if !ok {
-			return nil, fmt.Errorf("cannot find trigger %q", triggerName)
+			ih.errorf(state.frontendData.Pos, "state %q: cannot find %s reaction trigger %q", state.Name, kind, triggerName)
+			continue
 		}
 		triggers = append(triggers, trigger)
 	}
@@ -179,21 +259,20 @@ func (ih *inputHandler) collectReactions(triggerNames []string) ([]*StateReactio
 		return &StateReaction{
 			Trigger: t,
 		}
-	}), nil
+	})
 }
 
-func (ih *inputHandler) collectTransitions() error {
+func (ih *inputHandler) collectTransitions() {
 	// We go over all the transitions and generate the actual mapping.
 	for _, tdata := range ih.scdata.Transitions {
 		transition, fromState, err := ih.createTransition(tdata)
 		if err != nil {
-			return fmt.Errorf("creating transition %q: %w", tdata.String(), err)
+			ih.errorf(tdata.Pos, "%s: %v", tdata.String(), err)
+			continue
 		}
 
 		fromState.Transitions = append(fromState.Transitions, transition)
 	}
-
-	return nil
 }
 
 // createTransition returns a new transition, as well as the state it stems from.
@@ -218,10 +297,25 @@ func (ih *inputHandler) createTransition(tdata *frontend.TransitionData) (*Trans
 		trigger = t
 	}
 
+	if tdata.Guard != "" {
+		if err := validateIdentifier(tdata.Guard); err != nil {
+			return nil, nil, fmt.Errorf("invalid guard name %q: %w", tdata.Guard, err)
+		}
+	}
+
+	for _, action := range tdata.Actions {
+		if err := validateIdentifier(action); err != nil {
+			return nil, nil, fmt.Errorf("invalid action name %q: %w", action, err)
+		}
+	}
+
 	transition := &Transition{
 		From:         from,
 		To:           to,
 		Trigger:      trigger,
+		Guard:        tdata.Guard,
+		Actions:      tdata.Actions,
+		Internal:     tdata.Internal,
 		frontendData: tdata,
 	}
 
@@ -20,6 +20,14 @@ type Statechart struct {
 	// States are all the states, as defined in the order from the frontend.
 	States []*State
 
+	// Transitions are all the transitions, following the document order of their source states and
+	// then declaration order.
+	Transitions []*Transition
+
+	// Diagnostics are the warnings found while processing the statechart. Errors make the processing
+	// fail instead.
+	Diagnostics Diagnostics
+
 	TriggerMap   map[string]*Trigger
 	StateMap     map[string]*State
 	frontendData *frontend.StatechartData
@@ -35,6 +43,24 @@ func (sc *Statechart) InitialState() *State {
 	panic("No initial state found. This should've been caught in validation")
 }
 
+// DocumentOrder returns all the states in pre-order: parents come before their children, and
+// siblings keep the order in which they were defined.
+func (sc *Statechart) DocumentOrder() []*State {
+	return documentOrder(sc.Roots)
+}
+
+// InitialEntries returns the states that get entered when the statechart is activated, outermost
+// first.
+func (sc *Statechart) InitialEntries() []*State {
+	return entryOrder(nil, sc.InitialState(), true)
+}
+
+// ExitOrder returns all the states in the order they would be exited when deactivating the
+// statechart. Only the active ones should actually get exited.
+func (sc *Statechart) ExitOrder() []*State {
+	return exitOrder(sc.Roots)
+}
+
 // TRIGGER -----------------------------------------------------------------------------------------
 
 type Trigger struct {
@@ -79,6 +105,18 @@ type State struct {
 	Name    string
 	Initial bool
 
+	// Parallel means that all the children of this state are orthogonal regions: when the state is
+	// active, all of its children are active as well, each with its own active substates.
+	Parallel bool
+
+	// History marks this state as a history pseudo-state of its parent. These states never become
+	// active: transitions targeting them enter the parent restoring the last active substates.
+	History HistoryKind
+
+	// Final marks this state as an intended dead end: once entered, the statechart is not expected to
+	// leave it. Only atomic states can be final.
+	Final bool
+
 	// States represents the substates that this state has.
 	Children    []*State
 	Transitions []*Transition
@@ -97,8 +135,10 @@ func (s *State) Equals(other *State) bool {
 	return s.Name == other.Name
 }
 
+// InitialChild returns the child that gets entered when entering this state. Atomic and parallel
+// states have no initial child, as for the latter all the children get entered.
 func (s *State) InitialChild() *State {
-	if len(s.Children) == 0 {
+	if len(s.Children) == 0 || s.Parallel {
 		return nil
 	}
 
@@ -111,6 +151,55 @@ func (s *State) InitialChild() *State {
 	panic("No initial child found. This should've been caught in validation")
 }
 
+// IsHistory returns whether this state is a history pseudo-state.
+func (s *State) IsHistory() bool {
+	return s.History != History_None
+}
+
+// HistoryChildren returns the history pseudo-states of this state. There is at most one per kind.
+func (s *State) HistoryChildren() []*State {
+	var histories []*State
+	for _, child := range s.Children {
+		if child.IsHistory() {
+			histories = append(histories, child)
+		}
+	}
+
+	return histories
+}
+
+// IsAtomic returns whether this state has no substates.
+func (s *State) IsAtomic() bool {
+	return len(s.Children) == 0
+}
+
+// IsRegion returns whether this state is one of the orthogonal regions of a parallel state.
+func (s *State) IsRegion() bool {
+	return s.Parent != nil && s.Parent.Parallel
+}
+
+// IsDescendantOf returns whether this state is a proper descendant of |ancestor|.
+func (s *State) IsDescendantOf(ancestor *State) bool {
+	for current := s.Parent; current != nil; current = current.Parent {
+		if current == ancestor {
+			return true
+		}
+	}
+
+	return false
+}
+
+// DefaultEntries returns the substates that get entered, outermost first, when this state is entered
+// without a specific target within it. The state itself is not included.
+func (s *State) DefaultEntries() []*State {
+	return defaultEntries(s)
+}
+
+// Descendants returns all the substates of this state, at any depth, in document order.
+func (s *State) Descendants() []*State {
+	return documentOrder(s.Children)
+}
+
 func (s *State) IsParentOf(other *State) bool {
 	for _, child := range s.Children {
 		if child.Equals(other) {
@@ -128,6 +217,33 @@ func (s *State) ParentName() string {
 	return "None"
 }
 
+// HistoryKind represents what a history pseudo-state remembers of its parent.
+type HistoryKind int
+
+const (
+	History_None HistoryKind = iota
+
+	// History_Shallow remembers only the direct child of the parent that was last active. That child
+	// is entered through its initial substates.
+	History_Shallow
+
+	// History_Deep remembers all the active descendants of the parent, at any depth.
+	History_Deep
+)
+
+func (hk HistoryKind) String() string {
+	switch hk {
+	case History_None:
+		return "none"
+	case History_Shallow:
+		return "shallow"
+	case History_Deep:
+		return "deep"
+	}
+
+	return fmt.Sprintf("<invalid history kind %d>", int(hk))
+}
+
 // STATE REACTION ----------------------------------------------------------------------------------
 
 type StateReaction struct {
@@ -142,9 +258,70 @@ type Transition struct {
 	To      *State
 	Trigger *Trigger
 
+	// Guard is the name of the predicate, provided by the owner of the statechart, that has to pass
+	// for the transition to be taken. It receives the arguments of the trigger. Empty means the
+	// transition is always enabled.
+	// When several transitions are candidates for the same trigger, they are evaluated in
+	// declaration order and the first one whose guard passes is taken.
+	Guard string
+
+	// Actions are the names of the callbacks, provided by the owner of the statechart, that run when
+	// the transition is taken. They receive the arguments of the trigger and are called in order,
+	// after the source configuration has been exited and before the target one is entered.
+	Actions []string
+
+	// Internal transitions do not exit their source state. Their target has to be either the source
+	// itself, in which case nothing gets exited nor entered, or one of its descendants.
+	Internal bool
+
+	// The following are computed by the IR once the statechart is validated (see transitions.go), so
+	// that all backends share the same semantics.
+
+	// LCA is the least common ancestor the transition happens within: the innermost non-parallel
+	// state that contains both source and target. It is neither exited nor entered. For internal
+	// transitions it is the source itself. nil means the top level of the statechart.
+	LCA *State
+
+	// Exits are all the states that could be active under the LCA, innermost first. Only the ones
+	// that are active at the moment of the transition should get exited.
+	Exits []*State
+
+	// Entries are the states that get entered, outermost first. This includes all the regions of any
+	// parallel state being entered and the initial substates of the target. If the target is a
+	// history state, the entries stop at its parent and the rest has to be restored from history.
+	Entries []*State
+
 	frontendData *frontend.TransitionData
 }
 
 func (t *Transition) IsNullTransition() bool {
 	return t.Trigger == nil
 }
+
+func (t *Transition) HasGuard() bool {
+	return t.Guard != ""
+}
+
+// TargetsHistory returns whether the target of the transition is a history pseudo-state.
+func (t *Transition) TargetsHistory() bool {
+	return t.To.IsHistory()
+}
+
+// EnteredTarget returns the innermost state that the transition explicitly enters. This is the
+// target, unless it is a history state, in which case it is its parent.
+func (t *Transition) EnteredTarget() *State {
+	if t.To.IsHistory() {
+		return t.To.Parent
+	}
+	return t.To
+}
+
+// RecordedHistories returns the history states whose parent could be exited by this transition.
+// Their values have to be recorded before exiting anything.
+func (t *Transition) RecordedHistories() []*State {
+	var histories []*State
+	for _, state := range t.Exits {
+		histories = append(histories, state.HistoryChildren()...)
+	}
+	return histories
+}
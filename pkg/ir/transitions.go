@@ -0,0 +1,164 @@
+package ir
+
+// This file holds the pass that resolves which states each transition exits and enters, so that
+// every backend follows the exact same semantics.
+//
+// The rules are:
+// - A transition happens within its LCA (least common ancestor): the innermost non-parallel state
+//   that is a proper ancestor of both its source and target. The LCA is neither exited nor entered.
+//   Parallel states are skipped, as it is not possible to exit only some of their regions.
+// - Internal transitions use their source as the LCA, so the source is not exited. An internal
+//   transition to its own source exits and enters nothing.
+// - Every active state under the LCA gets exited, innermost first and regions in reverse document
+//   order.
+// - The path from the LCA to the target gets entered outermost first. All the regions of parallel
+//   states get entered, and the target is entered through its initial children.
+// - Targeting a history state means entering its parent, whose substates are then restored from
+//   what was recorded when the parent was last exited.
+
+// resolveTransitions fills the LCA, exits and entries of every transition in the statechart.
+// Requires the statechart to be already validated.
+func resolveTransitions(sc *Statechart) {
+	for _, transition := range sc.Transitions {
+		resolveTransition(sc, transition)
+	}
+}
+
+func resolveTransition(sc *Statechart, transition *Transition) {
+	target := transition.EnteredTarget()
+
+	if transition.Internal {
+		transition.LCA = transition.From
+
+		// A transition to itself does not change the configuration.
+		if transition.To == transition.From {
+			return
+		}
+	} else {
+		transition.LCA = leastCommonAncestor(transition.From, target)
+	}
+
+	exitScope := sc.Roots
+	if transition.LCA != nil {
+		exitScope = transition.LCA.Children
+	}
+	transition.Exits = exitOrder(exitScope)
+	transition.Entries = entryOrder(transition.LCA, target, !transition.To.IsHistory())
+}
+
+// leastCommonAncestor returns the innermost non-parallel state that is a proper ancestor of both
+// states. nil means the top level of the statechart.
+func leastCommonAncestor(from, to *State) *State {
+	for ancestor := from.Parent; ancestor != nil; ancestor = ancestor.Parent {
+		if ancestor.Parallel {
+			continue
+		}
+
+		if to.IsDescendantOf(ancestor) {
+			return ancestor
+		}
+	}
+
+	return nil
+}
+
+// documentOrder returns the states in pre-order: parents come before their children, and siblings
+// keep the order in which they were defined.
+func documentOrder(states []*State) []*State {
+	var result []*State
+	for _, state := range states {
+		result = append(result, state)
+		result = append(result, documentOrder(state.Children)...)
+	}
+	return result
+}
+
+// exitOrder returns the states and all of their descendants in the order they should be exited:
+// children before their parents, and siblings (eg. parallel regions) in reverse document order.
+// History states are skipped, as they are never active.
+func exitOrder(states []*State) []*State {
+	var result []*State
+	for i := len(states) - 1; i >= 0; i-- {
+		if states[i].IsHistory() {
+			continue
+		}
+
+		result = append(result, exitOrder(states[i].Children)...)
+		result = append(result, states[i])
+	}
+	return result
+}
+
+// entryOrder returns the states that get entered when going to |target| from within |lca| (nil
+// meaning the top level), outermost first. Any parallel state entered gets all of its regions
+// entered. If |descend| is set, the target gets entered through its initial children.
+func entryOrder(lca, target *State, descend bool) []*State {
+	// Collect the path from the LCA (exclusive) to the target.
+	var path []*State
+	for current := target; current != lca; current = current.Parent {
+		path = append([]*State{current}, path...)
+	}
+
+	var entries []*State
+	var enter func(state *State, rest []*State)
+	enter = func(state *State, rest []*State) {
+		entries = append(entries, state)
+
+		// If we're still on the path to the target, we follow it.
+		if len(rest) > 0 {
+			enterChildren(state, rest, enter)
+			return
+		}
+
+		// Otherwise we do the default entry.
+		if state == target && !descend {
+			return
+		}
+		enterChildren(state, nil, enter)
+	}
+
+	// Only internal transitions can have a parallel LCA. In that case all the regions got exited, so
+	// all of them have to be entered again.
+	if lca != nil && lca.Parallel {
+		enterChildren(lca, path, enter)
+	} else {
+		enter(path[0], path[1:])
+	}
+
+	return entries
+}
+
+// defaultEntries returns the substates of |state| that get entered by default, outermost first.
+func defaultEntries(state *State) []*State {
+	var entries []*State
+	var enter func(state *State, rest []*State)
+	enter = func(state *State, rest []*State) {
+		entries = append(entries, state)
+		enterChildren(state, rest, enter)
+	}
+	enterChildren(state, nil, enter)
+
+	return entries
+}
+
+// enterChildren enters the children of |state| that correspond, either following |path| if given,
+// or doing the default entry.
+func enterChildren(state *State, path []*State, enter func(*State, []*State)) {
+	if !state.Parallel {
+		if len(path) > 0 {
+			enter(path[0], path[1:])
+		} else if initial := state.InitialChild(); initial != nil {
+			enter(initial, nil)
+		}
+		return
+	}
+
+	// In parallel states, every region gets entered.
+	for _, region := range state.Children {
+		if len(path) > 0 && region == path[0] {
+			enter(region, path[1:])
+		} else {
+			enter(region, nil)
+		}
+	}
+}
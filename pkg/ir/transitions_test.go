@@ -0,0 +1,235 @@
+package ir
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/cristiandonosoc/gochart/pkg/frontend/yaml"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type wantTransition struct {
+	from    string
+	to      string
+	lca     string
+	exits   []string
+	entries []string
+}
+
+func readStatechart(t *testing.T, path string) *Statechart {
+	t.Helper()
+
+	yf := yaml.NewYamlFrontend()
+	scdata, err := yf.ProcessFromFile(path)
+	require.NoError(t, err)
+
+	sc, err := ProcessStatechartData(scdata)
+	require.NoError(t, err)
+
+	return sc
+}
+
+func stateNames(states []*State) []string {
+	var names []string
+	for _, state := range states {
+		names = append(names, state.Name)
+	}
+	return names
+}
+
+func assertTransitions(t *testing.T, sc *Statechart, want []wantTransition) {
+	t.Helper()
+
+	require.Len(t, sc.Transitions, len(want))
+	for i, transition := range sc.Transitions {
+		assert.Equal(t, want[i].from, transition.From.Name, "transition %d", i)
+		assert.Equal(t, want[i].to, transition.To.Name, "transition %d", i)
+
+		lca := ""
+		if transition.LCA != nil {
+			lca = transition.LCA.Name
+		}
+		assert.Equal(t, want[i].lca, lca, "transition %d LCA", i)
+		assert.Equal(t, want[i].exits, stateNames(transition.Exits), "transition %d exits", i)
+		assert.Equal(t, want[i].entries, stateNames(transition.Entries), "transition %d entries", i)
+	}
+}
+
+func TestResolveParallelTransitions(t *testing.T) {
+	sc := readStatechart(t, "testdata/parallel.yaml")
+
+	assert.Equal(t, []string{"Alive", "Movement", "Idle", "Weapon", "Ready"}, stateNames(sc.InitialEntries()))
+	assert.Equal(t,
+		[]string{"Dead", "Firing", "Ready", "Weapon", "Walking", "Idle", "Movement", "Alive"},
+		stateNames(sc.ExitOrder()))
+
+	// Transitions follow the document order of their source states.
+	assertTransitions(t, sc, []wantTransition{
+		{
+			from:    "Alive",
+			to:      "Dead",
+			exits:   []string{"Dead", "Firing", "Ready", "Weapon", "Walking", "Idle", "Movement", "Alive"},
+			entries: []string{"Dead"},
+		},
+		{
+			from:    "Idle",
+			to:      "Walking",
+			lca:     "Movement",
+			exits:   []string{"Walking", "Idle"},
+			entries: []string{"Walking"},
+		},
+		{
+			from:    "Walking",
+			to:      "Idle",
+			lca:     "Movement",
+			exits:   []string{"Walking", "Idle"},
+			entries: []string{"Idle"},
+		},
+		{
+			from:    "Ready",
+			to:      "Firing",
+			lca:     "Weapon",
+			exits:   []string{"Firing", "Ready"},
+			entries: []string{"Firing"},
+		},
+		{
+			from:    "Firing",
+			to:      "Ready",
+			lca:     "Weapon",
+			exits:   []string{"Firing", "Ready"},
+			entries: []string{"Ready"},
+		},
+	})
+}
+
+func TestResolveHistoryTransitions(t *testing.T) {
+	sc := readStatechart(t, "testdata/history.yaml")
+
+	// Entering a history state stops at its parent.
+	playingExits := []string{"Paused", "Ranged", "Melee", "Combat", "Explore", "Playing"}
+	assertTransitions(t, sc, []wantTransition{
+		{
+			from:    "Playing",
+			to:      "Paused",
+			exits:   playingExits,
+			entries: []string{"Paused"},
+		},
+		{
+			from:    "Explore",
+			to:      "Combat",
+			lca:     "Playing",
+			exits:   []string{"Ranged", "Melee", "Combat", "Explore"},
+			entries: []string{"Combat", "Melee"},
+		},
+		{
+			from:    "Melee",
+			to:      "Ranged",
+			lca:     "Combat",
+			exits:   []string{"Ranged", "Melee"},
+			entries: []string{"Ranged"},
+		},
+		{
+			from:    "Paused",
+			to:      "PlayingDeep",
+			exits:   playingExits,
+			entries: []string{"Playing"},
+		},
+		{
+			from:    "Paused",
+			to:      "PlayingShallow",
+			exits:   playingExits,
+			entries: []string{"Playing"},
+		},
+	})
+
+	pause := sc.Transitions[0]
+	assert.False(t, pause.TargetsHistory())
+	assert.Equal(t, []string{"PlayingShallow", "PlayingDeep"}, stateNames(pause.RecordedHistories()))
+
+	resume := sc.Transitions[3]
+	assert.True(t, resume.TargetsHistory())
+	assert.Equal(t, "Playing", resume.EnteredTarget().Name)
+
+	playing := sc.StateMap["Playing"]
+	assert.Equal(t, []string{"Explore"}, stateNames(playing.DefaultEntries()))
+	assert.Equal(t,
+		[]string{"Explore", "Combat", "Melee", "Ranged", "PlayingShallow", "PlayingDeep"},
+		stateNames(playing.Descendants()))
+}
+
+func TestResolveInternalTransitions(t *testing.T) {
+	sc := readStatechart(t, "testdata/internal.yaml")
+
+	assertTransitions(t, sc, []wantTransition{
+		// An internal transition to itself does not exit nor enter anything.
+		{
+			from: "Closed",
+			to:   "Closed",
+			lca:  "Closed",
+		},
+		// An internal transition to a substate does not exit its source.
+		{
+			from:    "Closed",
+			to:      "Locked",
+			lca:     "Closed",
+			exits:   []string{"Locked", "Unlocked"},
+			entries: []string{"Locked"},
+		},
+		// An external transition to itself exits and enters the source again.
+		{
+			from:    "Closed",
+			to:      "Closed",
+			exits:   []string{"Open", "Locked", "Unlocked", "Closed"},
+			entries: []string{"Closed", "Unlocked"},
+		},
+	})
+}
+
+func TestResolveInternalParallelTransition(t *testing.T) {
+	input := `
+name: Test
+triggers:
+  - name: Go
+states:
+  - name: P
+    initial: true
+    parallel: true
+  - name: R1
+    parent: P
+  - name: R1A
+    parent: R1
+    initial: true
+  - name: R1B
+    parent: R1
+  - name: R2
+    parent: P
+  - name: R2A
+    parent: R2
+    initial: true
+transitions:
+  - from: P
+    to: R1B
+    trigger: Go
+    internal: true
+`
+
+	yf := yaml.NewYamlFrontend()
+	scdata, err := yf.Process(strings.NewReader(input))
+	require.NoError(t, err)
+
+	sc, err := ProcessStatechartData(scdata)
+	require.NoError(t, err)
+
+	// All the regions get exited, so all of them have to be entered again.
+	assertTransitions(t, sc, []wantTransition{
+		{
+			from:    "P",
+			to:      "R1B",
+			lca:     "P",
+			exits:   []string{"R2A", "R2", "R1B", "R1A", "R1"},
+			entries: []string{"R1", "R1B", "R2", "R2A"},
+		},
+	})
+}
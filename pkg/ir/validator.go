@@ -2,61 +2,219 @@ package ir
 
 import (
 	"fmt"
+	"unicode"
 )
 
-func validate(ih *inputHandler) error {
+// validate checks the structure of the statechart, reporting every problem found.
+func validate(ih *inputHandler) {
 	// Validate top level statechart.
-	if err := validateTopLevelStatechart(ih); err != nil {
-		return fmt.Errorf("validating top level statechart: %w", err)
-	}
+	validateTopLevelStatechart(ih)
 
 	// Validate states.
 	for _, state := range ih.states {
-		if err := validateState(state); err != nil {
-			return fmt.Errorf("validating state %q: %w", state.Name, err)
+		validateState(ih, state)
+
+		for _, transition := range state.Transitions {
+			validateTransition(ih, transition)
 		}
 	}
-
-	return nil
 }
 
-func validateTopLevelStatechart(ih *inputHandler) error {
+func validateTopLevelStatechart(ih *inputHandler) {
 	if len(ih.rootStates) == 0 {
-		return fmt.Errorf("no root states found")
+		ih.errorf(ih.scdata.Pos, "no root states found")
+		return
 	}
 
-	if err := validateInitialExists(ih.rootStates); err != nil {
-		return fmt.Errorf("validating that initial child exists: %w", err)
+	validateInitialExists(ih, ih.rootStates, func() {
+		ih.errorf(ih.scdata.Pos, "no initial state found among the root states")
+	})
+}
+
+func validateState(ih *inputHandler, state *State) {
+	if state.IsHistory() {
+		validateHistoryState(ih, state)
+		return
 	}
 
-	return nil
-}
+	// There can only be one history pseudo-state of each kind per state.
+	validateHistoryChildren(ih, state)
+
+	if state.Final {
+		validateFinalState(ih, state)
+	}
+
+	if state.Parallel {
+		validateParallelState(ih, state)
+		return
+	}
 
-func validateState(state *State) error {
 	// If it has children, at least one of them has to be marked initial.
 	if len(state.Children) > 0 {
-		if err := validateInitialExists(state.Children); err != nil {
-			return fmt.Errorf("validating that initial child exists: %w", err)
+		validateInitialExists(ih, state.Children, func() {
+			ih.errorf(state.frontendData.Pos, "state %q has no initial child", state.Name)
+		})
+	}
+}
+
+// validateParallelState checks that every child of a parallel state is a proper region: all of them
+// get entered together, so none can be initial, and each needs its own initial substate.
+// The initial substate itself is checked when validating the region as a state.
+func validateParallelState(ih *inputHandler, state *State) {
+	if len(state.Children) == 0 {
+		ih.errorf(state.frontendData.Pos, "parallel state %q has no regions", state.Name)
+		return
+	}
+
+	for _, region := range state.Children {
+		pos := region.frontendData.Pos
+
+		if region.IsHistory() {
+			ih.errorf(pos, "history state %q cannot be a region, it should be placed within one", region.Name)
+			continue
+		}
+
+		if region.Initial {
+			ih.errorf(pos, "region %q cannot be marked initial, as all regions are entered", region.Name)
+		}
+
+		if len(region.Children) == 0 {
+			ih.errorf(pos, "region %q has no initial child", region.Name)
 		}
 	}
+}
 
-	return nil
+// validateHistoryState checks that a history pseudo-state only lives within a composite state and
+// does not behave as a normal state, as it never becomes active.
+func validateHistoryState(ih *inputHandler, state *State) {
+	pos := state.frontendData.Pos
+
+	if state.Parent == nil {
+		ih.errorf(pos, "history state %q must be placed within a composite state", state.Name)
+	}
+
+	if state.Parallel {
+		ih.errorf(pos, "history state %q cannot be parallel", state.Name)
+	}
+
+	if state.Initial {
+		ih.errorf(pos, "history state %q cannot be marked initial", state.Name)
+	}
+
+	if state.Final {
+		ih.errorf(pos, "history state %q cannot be marked final", state.Name)
+	}
+
+	if len(state.Children) > 0 {
+		ih.errorf(pos, "history state %q cannot have children", state.Name)
+	}
+
+	if len(state.Transitions) > 0 {
+		ih.errorf(pos, "history state %q cannot have outgoing transitions", state.Name)
+	}
+
+	if state.DefaultEnter || state.DefaultExit || len(state.EnterReactions) > 0 || len(state.ExitReactions) > 0 {
+		ih.errorf(pos, "history state %q cannot have reactions", state.Name)
+	}
+}
+
+// validateFinalState checks that a final state is an actual dead end.
+func validateFinalState(ih *inputHandler, state *State) {
+	pos := state.frontendData.Pos
+
+	if len(state.Children) > 0 {
+		ih.errorf(pos, "final state %q cannot have children", state.Name)
+	}
+
+	if len(state.Transitions) > 0 {
+		ih.errorf(pos, "final state %q cannot have outgoing transitions", state.Name)
+	}
 }
 
-func validateInitialExists(states []*State) error {
-	initial := false
+func validateHistoryChildren(ih *inputHandler, state *State) {
+	histories := make(map[HistoryKind]*State)
+	for _, history := range state.HistoryChildren() {
+		if other, ok := histories[history.History]; ok {
+			ih.errorf(history.frontendData.Pos, "%s history state %q defined when %q is already the %s history of %q",
+				history.History, history.Name, other.Name, history.History, state.Name)
+			continue
+		}
+		histories[history.History] = history
+	}
+}
+
+// validateInitialExists checks that exactly one of |states| is marked initial. |onMissing| reports
+// the case where none is.
+func validateInitialExists(ih *inputHandler, states []*State, onMissing func()) {
+	var initial *State
 	for _, state := range states {
-		if state.Initial {
-			// There can only be one initial state.
-			if initial {
-				return fmt.Errorf("state %q marked as initial when an initial state already exists", state.Name)
-			}
-			initial = true
+		if !state.Initial {
+			continue
 		}
+
+		// There can only be one initial state.
+		if initial != nil {
+			ih.errorf(state.frontendData.Pos, "state %q marked as initial when %q is already the initial state",
+				state.Name, initial.Name)
+			continue
+		}
+		initial = state
+	}
+
+	if initial == nil {
+		onMissing()
+	}
+}
+
+// validateTransition checks that internal transitions stay within their source state, as they do
+// not exit it.
+func validateTransition(ih *inputHandler, transition *Transition) {
+	if !transition.Internal {
+		return
+	}
+
+	tdata := transition.frontendData
+	if transition.To.IsHistory() {
+		ih.errorf(tdata.Pos, "%s: internal transitions cannot target history state %q",
+			tdata.String(), transition.To.Name)
+		return
+	}
+
+	if transition.To != transition.From && !transition.To.IsDescendantOf(transition.From) {
+		ih.errorf(tdata.Pos, "%s: internal transitions must target their source state or one of its substates, got %q",
+			tdata.String(), transition.To.Name)
+	}
+}
+
+// reservedNames are the names that the generated code uses for its own values, like the None value
+// of the state and trigger enums of the C++, Unreal, Go and C# backends.
+var reservedNames = map[string]bool{
+	"None": true,
+}
+
+// validateName checks that a state or trigger can be called |name| in the generated code.
+func validateName(name string) error {
+	if reservedNames[name] {
+		return fmt.Errorf("%q is reserved for the generated code", name)
 	}
+	return nil
+}
 
-	if !initial {
-		return fmt.Errorf("no initial state found")
+// validateIdentifier checks that a name given by the user can be used as an identifier in the
+// generated code (eg. the name of a guard, which becomes a method).
+func validateIdentifier(name string) error {
+	if name == "" {
+		return fmt.Errorf("identifiers cannot be empty")
+	}
+
+	for i, r := range name {
+		if i == 0 && unicode.IsDigit(r) {
+			return fmt.Errorf("identifiers cannot start with a digit")
+		}
+
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_' {
+			return fmt.Errorf("unexpected character %q", r)
+		}
 	}
 
 	return nil
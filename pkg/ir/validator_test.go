@@ -0,0 +1,374 @@
+package ir
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/cristiandonosoc/gochart/pkg/frontend/yaml"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadParallelYaml(t *testing.T) {
+	yf := yaml.NewYamlFrontend()
+
+	scdata, err := yf.ProcessFromFile("testdata/parallel.yaml")
+	require.NoError(t, err)
+
+	sc, err := ProcessStatechartData(scdata)
+	require.NoError(t, err)
+
+	alive := sc.StateMap["Alive"]
+	require.NotNil(t, alive)
+	assert.True(t, alive.Parallel)
+	assert.Nil(t, alive.InitialChild())
+
+	// Regions keep the order in which they were defined.
+	if assert.Len(t, alive.Children, 2) {
+		assert.Equal(t, "Movement", alive.Children[0].Name)
+		assert.Equal(t, "Weapon", alive.Children[1].Name)
+	}
+
+	for _, region := range alive.Children {
+		assert.True(t, region.IsRegion())
+		assert.NotNil(t, region.InitialChild())
+	}
+	assert.Equal(t, "Idle", sc.StateMap["Movement"].InitialChild().Name)
+	assert.Equal(t, "Ready", sc.StateMap["Weapon"].InitialChild().Name)
+}
+
+func TestReadHistoryYaml(t *testing.T) {
+	yf := yaml.NewYamlFrontend()
+
+	scdata, err := yf.ProcessFromFile("testdata/history.yaml")
+	require.NoError(t, err)
+
+	sc, err := ProcessStatechartData(scdata)
+	require.NoError(t, err)
+
+	assert.Equal(t, History_Shallow, sc.StateMap["PlayingShallow"].History)
+	assert.Equal(t, History_Deep, sc.StateMap["PlayingDeep"].History)
+	assert.Equal(t, History_None, sc.StateMap["Playing"].History)
+
+	histories := sc.StateMap["Playing"].HistoryChildren()
+	if assert.Len(t, histories, 2) {
+		assert.Equal(t, "PlayingShallow", histories[0].Name)
+		assert.Equal(t, "PlayingDeep", histories[1].Name)
+	}
+
+	// History states are never the initial child.
+	assert.Equal(t, "Explore", sc.StateMap["Playing"].InitialChild().Name)
+}
+
+func TestReadGuardsYaml(t *testing.T) {
+	yf := yaml.NewYamlFrontend()
+
+	scdata, err := yf.ProcessFromFile("testdata/guards.yaml")
+	require.NoError(t, err)
+
+	sc, err := ProcessStatechartData(scdata)
+	require.NoError(t, err)
+
+	// Transitions keep the declaration order, which is the order the guards are evaluated in.
+	ground := sc.StateMap["Ground"]
+	if assert.Len(t, ground.Transitions, 2) {
+		assert.Equal(t, "HighAir", ground.Transitions[0].To.Name)
+		assert.Equal(t, "IsHigh", ground.Transitions[0].Guard)
+		assert.Equal(t, "Air", ground.Transitions[1].To.Name)
+		assert.Equal(t, "CanJump", ground.Transitions[1].Guard)
+		assert.Equal(t, []string{"PlayJumpSound", "SpawnDust"}, ground.Transitions[1].Actions)
+	}
+
+	air := sc.StateMap["Air"]
+	if assert.Len(t, air.Transitions, 1) {
+		assert.False(t, air.Transitions[0].HasGuard())
+		assert.Equal(t, []string{"SpawnDust"}, air.Transitions[0].Actions)
+	}
+
+	stunned := sc.StateMap["Stunned"]
+	if assert.Len(t, stunned.Transitions, 1) {
+		assert.True(t, stunned.Transitions[0].IsNullTransition())
+		assert.Equal(t, "Recovered", stunned.Transitions[0].Guard)
+	}
+}
+
+func TestValidationErrors(t *testing.T) {
+	testcases := []struct {
+		name    string
+		input   string
+		wantErr string
+	}{
+		{
+			name: "no initial root",
+			input: `
+name: Test
+states:
+  - name: A
+`,
+			wantErr: "no initial state found",
+		},
+		{
+			name: "parallel without regions",
+			input: `
+name: Test
+states:
+  - name: A
+    initial: true
+    parallel: true
+`,
+			wantErr: `parallel state "A" has no regions`,
+		},
+		{
+			name: "region marked initial",
+			input: `
+name: Test
+states:
+  - name: A
+    initial: true
+    parallel: true
+  - name: R1
+    parent: A
+    initial: true
+  - name: R1A
+    parent: R1
+    initial: true
+`,
+			wantErr: `region "R1" cannot be marked initial`,
+		},
+		{
+			name: "region without children",
+			input: `
+name: Test
+states:
+  - name: A
+    initial: true
+    parallel: true
+  - name: R1
+    parent: A
+`,
+			wantErr: `region "R1" has no initial child`,
+		},
+		{
+			name: "region without initial child",
+			input: `
+name: Test
+states:
+  - name: A
+    initial: true
+    parallel: true
+  - name: R1
+    parent: A
+  - name: R1A
+    parent: R1
+`,
+			wantErr: `state "R1" has no initial child`,
+		},
+		{
+			name: "unknown history kind",
+			input: `
+name: Test
+states:
+  - name: A
+    initial: true
+  - name: H
+    parent: A
+    history: shalow
+`,
+			wantErr: `state "H": unknown history kind "shalow"`,
+		},
+		{
+			name: "history at the top level",
+			input: `
+name: Test
+states:
+  - name: A
+    initial: true
+  - name: H
+    history: deep
+`,
+			wantErr: `history state "H" must be placed within a composite state`,
+		},
+		{
+			name: "history as the only child",
+			input: `
+name: Test
+states:
+  - name: A
+    initial: true
+  - name: H
+    parent: A
+    history: deep
+`,
+			wantErr: `state "A" has no initial child`,
+		},
+		{
+			name: "history marked initial",
+			input: `
+name: Test
+states:
+  - name: A
+    initial: true
+  - name: B
+    parent: A
+  - name: H
+    parent: A
+    initial: true
+    history: deep
+`,
+			wantErr: `history state "H" cannot be marked initial`,
+		},
+		{
+			name: "history with outgoing transitions",
+			input: `
+name: Test
+states:
+  - name: A
+    initial: true
+  - name: B
+    parent: A
+    initial: true
+  - name: H
+    parent: A
+    history: shallow
+transitions:
+  - from: H
+    to: B
+`,
+			wantErr: `history state "H" cannot have outgoing transitions`,
+		},
+		{
+			name: "history with reactions",
+			input: `
+name: Test
+states:
+  - name: A
+    initial: true
+  - name: B
+    parent: A
+    initial: true
+  - name: H
+    parent: A
+    history: shallow
+    default_enter: true
+`,
+			wantErr: `history state "H" cannot have reactions`,
+		},
+		{
+			name: "two histories of the same kind",
+			input: `
+name: Test
+states:
+  - name: A
+    initial: true
+  - name: B
+    parent: A
+    initial: true
+  - name: H1
+    parent: A
+    history: shallow
+  - name: H2
+    parent: A
+    history: shallow
+`,
+			wantErr: `shallow history state "H2" defined when "H1" is already the shallow history of "A"`,
+		},
+		{
+			name: "history as a region",
+			input: `
+name: Test
+states:
+  - name: A
+    initial: true
+    parallel: true
+  - name: R1
+    parent: A
+  - name: R1A
+    parent: R1
+    initial: true
+  - name: H
+    parent: A
+    history: deep
+`,
+			wantErr: `history state "H" cannot be a region`,
+		},
+		{
+			name: "invalid guard name",
+			input: `
+name: Test
+states:
+  - name: A
+    initial: true
+  - name: B
+transitions:
+  - from: A
+    to: B
+    guard: "Can Jump"
+`,
+			wantErr: `invalid guard name "Can Jump": unexpected character ' '`,
+		},
+		{
+			name: "invalid action name",
+			input: `
+name: Test
+states:
+  - name: A
+    initial: true
+  - name: B
+transitions:
+  - from: A
+    to: B
+    actions: [Play, 2Fast]
+`,
+			wantErr: `invalid action name "2Fast": identifiers cannot start with a digit`,
+		},
+		{
+			name: "internal transition leaving its source",
+			input: `
+name: Test
+states:
+  - name: A
+    initial: true
+  - name: B
+transitions:
+  - from: A
+    to: B
+    internal: true
+`,
+			wantErr: `internal transitions must target their source state or one of its substates, got "B"`,
+		},
+		{
+			name: "internal transition to history",
+			input: `
+name: Test
+states:
+  - name: A
+    initial: true
+  - name: A1
+    parent: A
+    initial: true
+  - name: H
+    parent: A
+    history: shallow
+transitions:
+  - from: A
+    to: H
+    internal: true
+`,
+			wantErr: `internal transitions cannot target history state "H"`,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			yf := yaml.NewYamlFrontend()
+			scdata, err := yf.Process(strings.NewReader(tc.input))
+			require.NoError(t, err)
+
+			_, err = ProcessStatechartData(scdata)
+			if assert.Error(t, err) {
+				assert.Contains(t, err.Error(), tc.wantErr)
+			}
+		})
+	}
+}
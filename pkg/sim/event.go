@@ -0,0 +1,83 @@
+package sim
+
+import (
+	"fmt"
+
+	"github.com/cristiandonosoc/gochart/pkg/ir"
+)
+
+type EventKind int
+
+const (
+	// Event_Enter is a state becoming active.
+	Event_Enter EventKind = iota
+
+	// Event_Exit is a state becoming inactive.
+	Event_Exit
+
+	// Event_Guard is the evaluation of a transition guard.
+	Event_Guard
+
+	// Event_Action is the execution of a transition action.
+	Event_Action
+)
+
+func (ek EventKind) String() string {
+	switch ek {
+	case Event_Enter:
+		return "enter"
+	case Event_Exit:
+		return "exit"
+	case Event_Guard:
+		return "guard"
+	case Event_Action:
+		return "action"
+	}
+
+	return fmt.Sprintf("<invalid event kind %d>", int(ek))
+}
+
+// Event is a single step the statechart performed.
+type Event struct {
+	Kind EventKind
+
+	// State is the state entered or exited. nil for guards and actions.
+	State *ir.State
+
+	// Trigger is the trigger being processed, along with its argument values. nil when activating,
+	// deactivating or taking null transitions.
+	Trigger *ir.Trigger
+	Args    []any
+
+	// Callback is the name of the method that the generated code calls over its owner for this event
+	// (eg. "StateIdle_OnEnter" or "GuardCanJump"). Empty if there is none, as it happens when
+	// entering or exiting a state without reactions.
+	Callback string
+}
+
+// HasCallback returns whether the event calls into the owner of the statechart.
+func (e *Event) HasCallback() bool {
+	return e.Callback != ""
+}
+
+func (e *Event) String() string {
+	if e.State == nil {
+		return fmt.Sprintf("%s %s", e.Kind, e.Callback)
+	}
+
+	if e.HasCallback() {
+		return fmt.Sprintf("%s %s (%s)", e.Kind, e.State.Name, e.Callback)
+	}
+	return fmt.Sprintf("%s %s", e.Kind, e.State.Name)
+}
+
+// Callbacks returns the callbacks that the events call over the owner, in order.
+func Callbacks(events []*Event) []string {
+	var callbacks []string
+	for _, event := range events {
+		if event.HasCallback() {
+			callbacks = append(callbacks, event.Callback)
+		}
+	}
+	return callbacks
+}
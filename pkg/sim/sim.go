@@ -0,0 +1,489 @@
+// Package sim is an interpreter of the IR of a statechart. It permits to exercise a statechart
+// without generating and compiling code for it, and it is the reference semantics that the
+// generated code of every backend should follow.
+package sim
+
+import (
+	"fmt"
+
+	"github.com/cristiandonosoc/gochart/pkg/ir"
+)
+
+// maxNullTransitionSteps bounds how many rounds of null transitions can be taken after a single
+// step. Statecharts that go over it are considered to be looping forever.
+const maxNullTransitionSteps = 1000
+
+// GuardFunc evaluates a guard with the values of the arguments of the trigger being processed.
+type GuardFunc func(args []any) bool
+
+// ActionFunc runs a transition action with the values of the arguments of the trigger being
+// processed.
+type ActionFunc func(args []any)
+
+type SimulatorOptions struct {
+	Guards  map[string]GuardFunc
+	Actions map[string]ActionFunc
+}
+
+type Option func(*SimulatorOptions)
+
+// WithGuard provides the predicate to evaluate for the guard |name|. Every guard used by the
+// statechart has to be provided.
+func WithGuard(name string, guard GuardFunc) Option {
+	return func(options *SimulatorOptions) {
+		options.Guards[name] = guard
+	}
+}
+
+// WithAction provides a callback to run for the action |name|. Actions are always recorded as
+// events, so providing them is optional.
+func WithAction(name string, action ActionFunc) Option {
+	return func(options *SimulatorOptions) {
+		options.Actions[name] = action
+	}
+}
+
+// Simulator runs a statechart. It mirrors what the generated code does: the callbacks that the
+// generated code would call over its owner are recorded as events.
+type Simulator struct {
+	sc      *ir.Statechart
+	options *SimulatorOptions
+
+	active map[*ir.State]bool
+
+	// histories are the states recorded by each history state. A history that has not been recorded
+	// yet has no entry.
+	histories map[*ir.State]map[*ir.State]bool
+
+	events []*Event
+
+	// pending are the triggers raised while another one is being processed, which get processed in
+	// order once it is done. Unlike the generated C++ queue, it has no capacity, so the overflow
+	// policies of the C++ backend are not modeled.
+	pending    []*pendingTrigger
+	processing bool
+}
+
+// pendingTrigger is a trigger queued to be processed, along with its argument values.
+type pendingTrigger struct {
+	trigger *ir.Trigger
+	args    []any
+}
+
+func NewSimulator(sc *ir.Statechart, opts ...Option) *Simulator {
+	options := &SimulatorOptions{
+		Guards:  make(map[string]GuardFunc),
+		Actions: make(map[string]ActionFunc),
+	}
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	return &Simulator{
+		sc:        sc,
+		options:   options,
+		active:    make(map[*ir.State]bool),
+		histories: make(map[*ir.State]map[*ir.State]bool),
+	}
+}
+
+// Activate enters the initial configuration of the statechart and takes any enabled null
+// transition. The triggers raised meanwhile by the actions are processed afterwards.
+func (s *Simulator) Activate() error {
+	if s.IsActivated() {
+		return fmt.Errorf("statechart %q is already activated", s.sc.Name)
+	}
+
+	s.pending = nil
+	s.processing = true
+	s.histories = make(map[*ir.State]map[*ir.State]bool)
+	for _, state := range s.sc.InitialEntries() {
+		s.enter(state, nil, nil)
+	}
+
+	if err := s.runNullTransitions(); err != nil {
+		s.pending = nil
+		s.processing = false
+		return fmt.Errorf("activating: %w", err)
+	}
+
+	if err := s.processTriggers(); err != nil {
+		return fmt.Errorf("activating: %w", err)
+	}
+
+	return nil
+}
+
+// Deactivate exits all the active states. Pending triggers are dropped.
+func (s *Simulator) Deactivate() error {
+	if !s.IsActivated() {
+		return fmt.Errorf("statechart %q is not activated", s.sc.Name)
+	}
+
+	for _, state := range s.sc.ExitOrder() {
+		if s.active[state] {
+			s.exit(state, nil, nil)
+		}
+	}
+	s.pending = nil
+
+	return nil
+}
+
+// Trigger processes the trigger |name| with the given argument values, and then takes any enabled
+// null transition. As in the generated code, triggers are processed run-to-completion: one raised
+// while another one is being processed (eg. from an action) is queued, and processed once the
+// current one and the null transitions it enables are done. In that case Trigger returns once it is
+// queued, and the errors processing it are returned by the call that started processing.
+func (s *Simulator) Trigger(name string, args ...any) error {
+	// While a transition is being taken no state may be active, but the statechart still is.
+	if !s.processing && !s.IsActivated() {
+		return fmt.Errorf("statechart %q is not activated", s.sc.Name)
+	}
+
+	trigger, ok := s.sc.TriggerMap[name]
+	if !ok {
+		return fmt.Errorf("cannot find trigger %q", name)
+	}
+
+	if len(args) != len(trigger.Args) {
+		return fmt.Errorf("trigger %q expects %d arguments, got %d", name, len(trigger.Args), len(args))
+	}
+
+	s.pending = append(s.pending, &pendingTrigger{trigger: trigger, args: args})
+	if s.processing {
+		return nil
+	}
+
+	return s.processTriggers()
+}
+
+func (s *Simulator) IsActivated() bool {
+	for _, active := range s.active {
+		if active {
+			return true
+		}
+	}
+
+	return false
+}
+
+// IsActive returns whether the state |name| is active.
+func (s *Simulator) IsActive(name string) bool {
+	state, ok := s.sc.StateMap[name]
+	if !ok {
+		return false
+	}
+
+	return s.active[state]
+}
+
+// Configuration returns the active states in document order.
+func (s *Simulator) Configuration() []*ir.State {
+	var configuration []*ir.State
+	for _, state := range s.sc.DocumentOrder() {
+		if s.active[state] {
+			configuration = append(configuration, state)
+		}
+	}
+
+	return configuration
+}
+
+// Events returns all the events recorded so far.
+func (s *Simulator) Events() []*Event {
+	return s.events
+}
+
+// TakeEvents returns the events recorded so far and clears them.
+func (s *Simulator) TakeEvents() []*Event {
+	events := s.events
+	s.events = nil
+	return events
+}
+
+// DISPATCHING -------------------------------------------------------------------------------------
+
+// processTriggers processes the pending triggers in order until the queue is empty. If one of them
+// fails, the rest are dropped.
+func (s *Simulator) processTriggers() error {
+	s.processing = true
+	defer func() {
+		s.pending = nil
+		s.processing = false
+	}()
+
+	for len(s.pending) > 0 {
+		pending := s.pending[0]
+		s.pending = s.pending[1:]
+
+		name := pending.trigger.Name
+		if _, err := s.dispatch(pending.trigger, pending.args); err != nil {
+			return fmt.Errorf("dispatching trigger %q: %w", name, err)
+		}
+
+		if err := s.runNullTransitions(); err != nil {
+			return fmt.Errorf("after trigger %q: %w", name, err)
+		}
+	}
+
+	return nil
+}
+
+func (s *Simulator) runNullTransitions() error {
+	for i := 0; i < maxNullTransitionSteps; i++ {
+		taken, err := s.dispatch(nil, nil)
+		if err != nil {
+			return fmt.Errorf("dispatching null transitions: %w", err)
+		}
+
+		if !taken {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("null transitions did not settle after %d steps", maxNullTransitionSteps)
+}
+
+// dispatch selects and takes the transitions for |trigger| (nil for null transitions).
+// Each active atomic state, in document order, selects the first enabled transition of itself or
+// its ancestors: inner states win over their ancestors, and within a state the guards are evaluated
+// in declaration order. Once a transition is taken, all the states within its LCA are handled, so
+// orthogonal regions can each take a transition for the same trigger.
+func (s *Simulator) dispatch(trigger *ir.Trigger, args []any) (bool, error) {
+	// We select the atomic states before taking anything, as transitions change the configuration.
+	var atomics []*ir.State
+	for _, state := range s.sc.DocumentOrder() {
+		if state.IsAtomic() && !state.IsHistory() && s.active[state] {
+			atomics = append(atomics, state)
+		}
+	}
+
+	// Every guard that could get evaluated is looked up before taking anything, so that a missing
+	// one does not leave some regions transitioned and others not.
+	for _, atomic := range atomics {
+		if err := s.checkGuards(atomic, trigger); err != nil {
+			return false, fmt.Errorf("state %q: %w", atomic.Name, err)
+		}
+	}
+
+	handled := make(map[*ir.State]bool)
+	taken := false
+	for _, atomic := range atomics {
+		if handled[atomic] {
+			continue
+		}
+
+		transition := s.selectTransition(atomic, trigger, args)
+		if transition == nil {
+			continue
+		}
+
+		for _, state := range s.sc.States {
+			if transition.LCA == nil || state.IsDescendantOf(transition.LCA) {
+				handled[state] = true
+			}
+		}
+
+		s.execute(transition, args)
+		taken = true
+	}
+
+	return taken, nil
+}
+
+// checkGuards returns an error if any of the guards of the transitions |atomic| could take for
+// |trigger| has not been provided.
+func (s *Simulator) checkGuards(atomic *ir.State, trigger *ir.Trigger) error {
+	for current := atomic; current != nil; current = current.Parent {
+		for _, transition := range current.Transitions {
+			if transition.Trigger != trigger || !transition.HasGuard() {
+				continue
+			}
+
+			if _, ok := s.options.Guards[transition.Guard]; !ok {
+				return fmt.Errorf("guard %q not provided", transition.Guard)
+			}
+		}
+	}
+
+	return nil
+}
+
+// selectTransition returns the transition |atomic| takes for |trigger|, or nil if there is none.
+// The guards have to be checked with checkGuards beforehand.
+func (s *Simulator) selectTransition(atomic *ir.State, trigger *ir.Trigger, args []any) *ir.Transition {
+	for current := atomic; current != nil; current = current.Parent {
+		for _, transition := range current.Transitions {
+			if transition.Trigger != trigger {
+				continue
+			}
+
+			if !transition.HasGuard() {
+				return transition
+			}
+
+			s.record(&Event{
+				Kind:     Event_Guard,
+				Trigger:  trigger,
+				Args:     args,
+				Callback: "Guard" + transition.Guard,
+			})
+			if s.options.Guards[transition.Guard](args) {
+				return transition
+			}
+		}
+	}
+
+	return nil
+}
+
+// execute takes a transition, following the exits and entries resolved by the IR.
+func (s *Simulator) execute(transition *ir.Transition, args []any) {
+	trigger := transition.Trigger
+
+	// History gets recorded before anything is exited.
+	for _, history := range transition.RecordedHistories() {
+		if s.active[history.Parent] {
+			s.recordHistory(history)
+		}
+	}
+
+	for _, state := range transition.Exits {
+		if s.active[state] {
+			s.exit(state, trigger, args)
+		}
+	}
+
+	for _, action := range transition.Actions {
+		s.record(&Event{
+			Kind:     Event_Action,
+			Trigger:  trigger,
+			Args:     args,
+			Callback: "Action" + action,
+		})
+		if fn, ok := s.options.Actions[action]; ok {
+			fn(args)
+		}
+	}
+
+	for _, state := range transition.Entries {
+		s.enter(state, trigger, args)
+	}
+
+	if transition.TargetsHistory() {
+		s.restoreHistory(transition.To, trigger, args)
+	}
+}
+
+// HISTORY -----------------------------------------------------------------------------------------
+
+func (s *Simulator) recordHistory(history *ir.State) {
+	parent := history.Parent
+
+	// Shallow history only remembers the direct children of the parent.
+	tracked := parent.Children
+	if history.History == ir.History_Deep {
+		tracked = parent.Descendants()
+	}
+
+	record := make(map[*ir.State]bool)
+	for _, state := range tracked {
+		if s.active[state] {
+			record[state] = true
+		}
+	}
+	s.histories[history] = record
+}
+
+func (s *Simulator) restoreHistory(history *ir.State, trigger *ir.Trigger, args []any) {
+	parent := history.Parent
+
+	record, ok := s.histories[history]
+	if !ok {
+		for _, state := range parent.DefaultEntries() {
+			s.enter(state, trigger, args)
+		}
+		return
+	}
+
+	switch history.History {
+	case ir.History_Shallow:
+		for _, child := range parent.Children {
+			if !record[child] {
+				continue
+			}
+
+			s.enter(child, trigger, args)
+			for _, state := range child.DefaultEntries() {
+				s.enter(state, trigger, args)
+			}
+		}
+	case ir.History_Deep:
+		// All the active descendants were recorded, so entering them in document order restores the
+		// whole configuration.
+		for _, state := range parent.Descendants() {
+			if record[state] {
+				s.enter(state, trigger, args)
+			}
+		}
+	}
+}
+
+// CALLBACKS ---------------------------------------------------------------------------------------
+
+func (s *Simulator) enter(state *ir.State, trigger *ir.Trigger, args []any) {
+	s.active[state] = true
+
+	event := &Event{
+		Kind:    Event_Enter,
+		State:   state,
+		Trigger: trigger,
+		Args:    args,
+	}
+
+	// A reaction specific to the trigger wins over the default one.
+	if hasReaction(state.EnterReactions, trigger) {
+		event.Callback = fmt.Sprintf("State%s_OnEnter_%s", state.Name, trigger.Name)
+	} else if state.DefaultEnter {
+		event.Callback = fmt.Sprintf("State%s_OnEnter", state.Name)
+	}
+
+	s.record(event)
+}
+
+func (s *Simulator) exit(state *ir.State, trigger *ir.Trigger, args []any) {
+	event := &Event{
+		Kind:    Event_Exit,
+		State:   state,
+		Trigger: trigger,
+		Args:    args,
+	}
+
+	// A reaction specific to the trigger wins over the default one.
+	if hasReaction(state.ExitReactions, trigger) {
+		event.Callback = fmt.Sprintf("State%s_OnExit_%s", state.Name, trigger.Name)
+	} else if state.DefaultExit {
+		event.Callback = fmt.Sprintf("State%s_OnExit", state.Name)
+	}
+
+	s.record(event)
+	s.active[state] = false
+}
+
+func (s *Simulator) record(event *Event) {
+	s.events = append(s.events, event)
+}
+
+func hasReaction(reactions []*ir.StateReaction, trigger *ir.Trigger) bool {
+	if trigger == nil {
+		return false
+	}
+
+	for _, reaction := range reactions {
+		if reaction.Trigger == trigger {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,356 @@
+package sim
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/cristiandonosoc/gochart/pkg/frontend/yaml"
+	"github.com/cristiandonosoc/gochart/pkg/ir"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// chartsDir has the charts shared by the tests of the IR, the simulator and the backends.
+const chartsDir = "../ir/testdata"
+
+func readStatechart(t *testing.T, path string) *ir.Statechart {
+	t.Helper()
+
+	yf := yaml.NewYamlFrontend()
+	scdata, err := yf.ProcessFromFile(path)
+	require.NoError(t, err)
+
+	sc, err := ir.ProcessStatechartData(scdata)
+	require.NoError(t, err)
+
+	return sc
+}
+
+func eventStrings(events []*Event) []string {
+	var strs []string
+	for _, event := range events {
+		strs = append(strs, event.String())
+	}
+	return strs
+}
+
+func configurationNames(s *Simulator) []string {
+	var names []string
+	for _, state := range s.Configuration() {
+		names = append(names, state.Name)
+	}
+	return names
+}
+
+func TestParallel(t *testing.T) {
+	s := NewSimulator(readStatechart(t, filepath.Join(chartsDir, "parallel.yaml")))
+
+	require.NoError(t, s.Activate())
+	assert.Equal(t, []string{"Alive", "Movement", "Idle", "Weapon", "Ready"}, configurationNames(s))
+	assert.Equal(t, []string{
+		"StateAlive_OnEnter",
+		"StateMovement_OnEnter",
+		"StateIdle_OnEnter",
+		"StateWeapon_OnEnter",
+		"StateReady_OnEnter",
+	}, Callbacks(s.TakeEvents()))
+
+	// Walking reacts specifically to Move.
+	require.NoError(t, s.Trigger("Move", 1.5))
+	events := s.TakeEvents()
+	assert.Equal(t, []string{
+		"exit Idle (StateIdle_OnExit)",
+		"enter Walking (StateWalking_OnEnter_Move)",
+	}, eventStrings(events))
+	assert.Equal(t, []any{1.5}, events[1].Args)
+
+	// The other region is not affected.
+	require.NoError(t, s.Trigger("Fire"))
+	assert.Equal(t, []string{"Alive", "Movement", "Walking", "Weapon", "Firing"}, configurationNames(s))
+	s.TakeEvents()
+
+	// Leaving the parallel state exits every region, in reverse document order.
+	require.NoError(t, s.Trigger("Die"))
+	assert.Equal(t, []string{
+		"StateFiring_OnExit",
+		"StateWeapon_OnExit",
+		"StateWalking_OnExit",
+		"StateMovement_OnExit",
+		"StateAlive_OnExit",
+		"StateDead_OnEnter",
+	}, Callbacks(s.TakeEvents()))
+	assert.Equal(t, []string{"Dead"}, configurationNames(s))
+
+	require.NoError(t, s.Deactivate())
+	assert.False(t, s.IsActivated())
+}
+
+func TestHistory(t *testing.T) {
+	s := NewSimulator(readStatechart(t, filepath.Join(chartsDir, "history.yaml")))
+
+	require.NoError(t, s.Activate())
+	require.NoError(t, s.Trigger("Next"))
+	require.NoError(t, s.Trigger("Switch"))
+	assert.Equal(t, []string{"Playing", "Combat", "Ranged"}, configurationNames(s))
+
+	require.NoError(t, s.Trigger("Pause"))
+	assert.Equal(t, []string{"Paused"}, configurationNames(s))
+	s.TakeEvents()
+
+	// Deep history restores the whole configuration.
+	require.NoError(t, s.Trigger("Resume"))
+	assert.Equal(t, []string{
+		"exit Paused (StatePaused_OnExit)",
+		"enter Playing (StatePlaying_OnEnter)",
+		"enter Combat (StateCombat_OnEnter)",
+		"enter Ranged (StateRanged_OnEnter)",
+	}, eventStrings(s.TakeEvents()))
+
+	// Shallow history only restores the child, which is entered through its initial substate.
+	require.NoError(t, s.Trigger("Pause"))
+	require.NoError(t, s.Trigger("ResumeFresh"))
+	assert.Equal(t, []string{"Playing", "Combat", "Melee"}, configurationNames(s))
+}
+
+func TestHistoryWithoutRecord(t *testing.T) {
+	sc := readStatechart(t, filepath.Join(chartsDir, "history.yaml"))
+
+	// Make Paused the initial state so that Playing has never been exited when resuming.
+	sc.StateMap["Playing"].Initial = false
+	sc.StateMap["Paused"].Initial = true
+
+	s := NewSimulator(sc)
+	require.NoError(t, s.Activate())
+	require.NoError(t, s.Trigger("Resume"))
+	assert.Equal(t, []string{"Playing", "Explore"}, configurationNames(s))
+}
+
+func TestGuardsAndActions(t *testing.T) {
+	recovered := false
+	var actions []string
+	s := NewSimulator(readStatechart(t, filepath.Join(chartsDir, "guards.yaml")),
+		WithGuard("IsHigh", func(args []any) bool { return args[0].(int) > 10 }),
+		WithGuard("CanJump", func(args []any) bool { return true }),
+		WithGuard("IsHurt", func(args []any) bool { return true }),
+		WithGuard("Recovered", func(args []any) bool { return recovered }),
+		WithAction("SpawnDust", func(args []any) { actions = append(actions, "SpawnDust") }))
+
+	require.NoError(t, s.Activate())
+	s.TakeEvents()
+
+	// Guards are evaluated in declaration order, and actions run between exit and entry.
+	require.NoError(t, s.Trigger("Jump", 3))
+	assert.Equal(t, []string{
+		"guard GuardIsHigh",
+		"guard GuardCanJump",
+		"exit Ground",
+		"action ActionPlayJumpSound",
+		"action ActionSpawnDust",
+		"enter Air (StateAir_OnEnter)",
+	}, eventStrings(s.TakeEvents()))
+	assert.Equal(t, []string{"SpawnDust"}, actions)
+
+	require.NoError(t, s.Trigger("Land"))
+	require.NoError(t, s.Trigger("Jump", 20))
+	assert.Equal(t, []string{"HighAir"}, configurationNames(s))
+	s.TakeEvents()
+
+	// Null transitions are evaluated after every trigger.
+	require.NoError(t, s.Trigger("Land"))
+	assert.Equal(t, []string{
+		"guard GuardIsHurt",
+		"exit HighAir",
+		"enter Stunned (StateStunned_OnEnter)",
+		"guard GuardRecovered",
+	}, eventStrings(s.TakeEvents()))
+
+	recovered = true
+	require.NoError(t, s.Trigger("Jump", 1))
+	assert.Equal(t, []string{"Ground"}, configurationNames(s))
+}
+
+// TestQueuedTriggers checks that a trigger raised by an action waits for the transition that runs it
+// to be completed, as it happens with the generated code.
+func TestQueuedTriggers(t *testing.T) {
+	var s *Simulator
+	s = NewSimulator(readStatechart(t, filepath.Join(chartsDir, "guards.yaml")),
+		WithGuard("IsHigh", func(args []any) bool { return false }),
+		WithGuard("CanJump", func(args []any) bool { return true }),
+		WithGuard("IsHurt", func(args []any) bool { return false }),
+		WithGuard("Recovered", func(args []any) bool { return false }),
+		WithAction("PlayJumpSound", func(args []any) { assert.NoError(t, s.Trigger("Land")) }))
+
+	require.NoError(t, s.Activate())
+	s.TakeEvents()
+
+	require.NoError(t, s.Trigger("Jump", 3))
+	assert.Equal(t, []string{
+		"guard GuardIsHigh",
+		"guard GuardCanJump",
+		"exit Ground",
+		"action ActionPlayJumpSound",
+		"action ActionSpawnDust",
+		"enter Air (StateAir_OnEnter)",
+		// Land is only processed once Air has been entered.
+		"exit Air",
+		"action ActionSpawnDust",
+		"enter Ground (StateGround_OnEnter)",
+	}, eventStrings(s.TakeEvents()))
+	assert.Equal(t, []string{"Ground"}, configurationNames(s))
+}
+
+func TestErrors(t *testing.T) {
+	sc := readStatechart(t, filepath.Join(chartsDir, "guards.yaml"))
+
+	s := NewSimulator(sc)
+	assert.ErrorContains(t, s.Trigger("Land"), `statechart "Jumper" is not activated`)
+
+	require.NoError(t, s.Activate())
+	assert.ErrorContains(t, s.Activate(), `statechart "Jumper" is already activated`)
+	assert.ErrorContains(t, s.Trigger("Fly"), `cannot find trigger "Fly"`)
+	assert.ErrorContains(t, s.Trigger("Jump"), `trigger "Jump" expects 1 arguments, got 0`)
+	assert.ErrorContains(t, s.Trigger("Jump", 1), `guard "IsHigh" not provided`)
+}
+
+func TestMissingGuardChangesNothing(t *testing.T) {
+	input := `
+name: Regions
+triggers:
+  - name: Go
+states:
+  - name: Root
+    initial: true
+    parallel: true
+  - name: Left
+    parent: Root
+  - name: L1
+    parent: Left
+    initial: true
+  - name: L2
+    parent: Left
+  - name: Right
+    parent: Root
+  - name: R1
+    parent: Right
+    initial: true
+  - name: R2
+    parent: Right
+transitions:
+  - from: L1
+    to: L2
+    trigger: Go
+  - from: R1
+    to: R2
+    trigger: Go
+    guard: Ready
+`
+
+	yf := yaml.NewYamlFrontend()
+	scdata, err := yf.Process(strings.NewReader(input))
+	require.NoError(t, err)
+
+	sc, err := ir.ProcessStatechartData(scdata)
+	require.NoError(t, err)
+
+	s := NewSimulator(sc)
+	require.NoError(t, s.Activate())
+	s.TakeEvents()
+
+	// The left region could transition, but it must not as the guard of the right one is missing.
+	assert.ErrorContains(t, s.Trigger("Go"), `state "R1": guard "Ready" not provided`)
+	assert.Equal(t, []string{"Root", "Left", "L1", "Right", "R1"}, configurationNames(s))
+	assert.Empty(t, s.TakeEvents())
+}
+
+func TestNullTransitionLoop(t *testing.T) {
+	input := `
+name: Loop
+states:
+  - name: A
+    initial: true
+  - name: B
+transitions:
+  - from: A
+    to: B
+  - from: B
+    to: A
+    guard: KeepLooping
+`
+
+	yf := yaml.NewYamlFrontend()
+	scdata, err := yf.Process(strings.NewReader(input))
+	require.NoError(t, err)
+
+	sc, err := ir.ProcessStatechartData(scdata)
+	require.NoError(t, err)
+
+	// The IR only warns about loops that depend on guards.
+	s := NewSimulator(sc, WithGuard("KeepLooping", func(args []any) bool { return true }))
+	assert.ErrorContains(t, s.Activate(), "null transitions did not settle")
+}
+
+// TestBackendRuns checks that the code generated by the backends follows the simulator, which is the
+// reference semantics. The backends run their generated code over the statecharts in
+// pkg/ir/testdata, and compare what it prints against the run golden files of the C++ backend. Here
+// we drive the simulator the same way and compare its trace against those same files.
+func TestBackendRuns(t *testing.T) {
+	charts, err := filepath.Glob(filepath.Join(chartsDir, "*.yaml"))
+	require.NoError(t, err)
+	require.NotEmpty(t, charts)
+
+	for _, chart := range charts {
+		name := strings.TrimSuffix(filepath.Base(chart), ".yaml")
+		t.Run(name, func(t *testing.T) {
+			want, err := os.ReadFile(filepath.Join("..", "backend", "cpp", "testdata", name+".run.golden"))
+			require.NoError(t, err)
+			assert.Equal(t, string(want), runTrace(t, readStatechart(t, chart)))
+		})
+	}
+}
+
+// runTrace drives |sc| as the run tests of the backends do: every guard passes, and every trigger is
+// fired twice in declaration order with zero values. It returns the callbacks and the active states
+// after each step, in the format of the run golden files.
+func runTrace(t *testing.T, sc *ir.Statechart) string {
+	t.Helper()
+
+	var opts []Option
+	for _, transition := range sc.Transitions {
+		if transition.HasGuard() {
+			opts = append(opts, WithGuard(transition.Guard, func(args []any) bool { return true }))
+		}
+	}
+	s := NewSimulator(sc, opts...)
+
+	var sb strings.Builder
+	step := func(title string, fn func() error) {
+		sb.WriteString(title + "\n")
+		require.NoError(t, fn(), title)
+		for _, callback := range Callbacks(s.TakeEvents()) {
+			fmt.Fprintf(&sb, "  %s\n", callback)
+		}
+
+		sb.WriteString("active:")
+		for _, state := range sc.States {
+			if s.IsActive(state.Name) {
+				sb.WriteString(" " + state.Name)
+			}
+		}
+		sb.WriteString("\n")
+	}
+
+	step("Activate", s.Activate)
+	for round := 0; round < 2; round++ {
+		for _, trigger := range sc.Triggers {
+			args := make([]any, len(trigger.Args))
+			step("Trigger"+trigger.Name, func() error { return s.Trigger(trigger.Name, args...) })
+		}
+	}
+	step("Deactivate", s.Deactivate)
+
+	return sb.String()
+}